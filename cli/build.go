@@ -4,6 +4,7 @@ import (
 	"github.com/alexandreh2ag/mib/cli/build"
 	"github.com/alexandreh2ag/mib/context"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func GetBuildCmd(ctx *context.Context) *cobra.Command {
@@ -12,10 +13,21 @@ func GetBuildCmd(ctx *context.Context) *cobra.Command {
 		Short: "build sub commands",
 	}
 	cmd.PersistentFlags().Bool(build.PushImages, false, "Push image to registry")
-	cmd.PersistentFlags().BoolP(build.DryRun, "d", false, "Dry run")
+	cmd.PersistentFlags().BoolP(build.DryRun, "d", false, "Print the build plan without building")
+	cmd.PersistentFlags().StringP(build.Output, "o", build.OutputText, "Output format of the dry run build plan (text, json)")
+	cmd.PersistentFlags().IntP(build.Jobs, "j", 1, "Number of images built at once")
+	cmd.PersistentFlags().BoolP(build.KeepGoing, "k", false, "Keep building images not depending on a failed image")
+	_ = viper.BindPFlag("build.jobs", cmd.PersistentFlags().Lookup(build.Jobs))
+	_ = viper.BindPFlag("build.keepGoing", cmd.PersistentFlags().Lookup(build.KeepGoing))
 
 	cmd.AddCommand(build.GetDirtyCmd(ctx))
 	cmd.AddCommand(build.GetCommitCmd(ctx))
+	cmd.AddCommand(build.GetRangeCmd(ctx))
+	cmd.AddCommand(build.GetBranchCmd(ctx))
+	cmd.AddCommand(build.GetPendingCmd(ctx))
+	cmd.AddCommand(build.GetHashCmd(ctx))
+	cmd.AddCommand(build.GetImageCmd(ctx))
+	cmd.AddCommand(build.GetAllCmd(ctx))
 
 	return cmd
 }
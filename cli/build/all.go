@@ -0,0 +1,59 @@
+package build
+
+import (
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/spf13/cobra"
+)
+
+func GetAllCmd(ctx *context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "all",
+		Short: "Build all images",
+		Long:  "Build all images in dependency order, for example to rebuild them on top of an updated base image. It does not rely on git.",
+		RunE:  GetAllRunFn(ctx),
+	}
+
+	cmd.Flags().StringSlice(Exclude, []string{}, "Do not build images whose name or dir match the glob")
+
+	return cmd
+}
+
+func GetAllRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool(DryRun)
+		pushImages, _ := cmd.Flags().GetBool(PushImages)
+		exclude, _ := cmd.Flags().GetStringSlice(Exclude)
+		builder := ctx.Builders.GetInstance(docker.KeyBuilder)
+
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+
+		images.FlagAll()
+		errExclude := images.UnflagExcluded(exclude)
+		if errExclude != nil {
+			return errExclude
+		}
+
+		if len(images) > 0 {
+			cmd.Println(printer.DisplayImagesTree(images))
+		}
+
+		if dryRun {
+			return printBuildPlan(cmd, builder, images, pushImages, nil)
+		}
+
+		errBuild := builder.BuildImages(images, pushImages)
+		printBuildSummary(cmd, images)
+		saveBuildStateAtHead(ctx, builder, images)
+		if errBuild != nil {
+			return errBuild
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,131 @@
+package build
+
+import (
+	"errors"
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	mock_types_container "github.com/alexandreh2ag/mib/mock/types/container"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+)
+
+func TestGetAllRunFn(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmdArgs []string
+		preFn   func(ctx *context.Context, ctrl *gomock.Controller)
+		checkFn func(t *testing.T, err error)
+	}{
+		{
+			name: "Success",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(true)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						assertImagesToBuild(t, []string{"foo:0.1", "bar:0.1"}, images)
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + PushImages},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "SuccessExclude",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						assertImagesToBuild(t, []string{"foo:0.1"}, images)
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + Exclude, "bar"},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "SuccessDryRun",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().GetBuildCommand(gomock.Any(), gomock.Eq(false)).Times(2).Return([]string{"docker", "build", "."}, nil)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + DryRun},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "FailLoadImages",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: "), 0644)
+				ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, err error) {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "configuration file is not valid")
+			},
+		},
+		{
+			name: "ErrorExclude",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			},
+			cmdArgs: []string{"--" + Exclude, "["},
+			checkFn: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "exclude pattern [ is not valid: syntax error in pattern")
+			},
+		},
+		{
+			name: "ErrorBuildImages",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).Return(errors.New("error"))
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			cmd := GetAllCmd(ctx)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.Flags().Bool(PushImages, false, "")
+			cmd.Flags().Bool(DryRun, false, "")
+			viper.Reset()
+			viper.SetFs(ctx.FS)
+
+			_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/bar/mib.yml", []byte("name: bar\ntag: 0.1"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/bar/Dockerfile", []byte("FROM foo:0.1"), 0644)
+
+			tt.preFn(ctx, ctrl)
+
+			cmd.SetArgs(tt.cmdArgs)
+			err := cmd.Execute()
+			tt.checkFn(t, err)
+		})
+	}
+}
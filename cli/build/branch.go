@@ -0,0 +1,66 @@
+package build
+
+import (
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/git"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+func GetBranchCmd(ctx *context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "branch",
+		Short: "Build image changed on current branch",
+		Long:  "Build image changed between the merge-base of HEAD and the base revision, and HEAD.",
+		RunE:  GetBranchRunFn(ctx),
+	}
+
+	AddBaseFlag(cmd, DefaultBase)
+
+	return cmd
+}
+
+func GetBranchRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool(DryRun)
+		pushImages, _ := cmd.Flags().GetBool(PushImages)
+		base, _ := cmd.Flags().GetString(Base)
+
+		builder := ctx.Builders.GetInstance(docker.KeyBuilder)
+		gitManager, errCreateGit := git.CreateGit(ctx)
+		if errCreateGit != nil {
+			return errCreateGit
+		}
+
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+		filesChanged, errGetChanged := gitManager.GetBranchFilesChanged(base)
+		if errGetChanged != nil {
+			return errGetChanged
+		}
+
+		images.FlagChanged(loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
+
+		if len(images) > 0 {
+			cmd.Println(printer.DisplayImagesTree(images))
+		}
+
+		if dryRun {
+			return printBuildPlan(cmd, builder, images, pushImages, nil)
+		}
+
+		errBuild := builder.BuildImages(images, pushImages)
+		printBuildSummary(cmd, images)
+		saveBuildState(ctx, builder, gitManager, images, string(plumbing.HEAD))
+		if errBuild != nil {
+			return errBuild
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,142 @@
+package build
+
+import (
+	"errors"
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	mibGit "github.com/alexandreh2ag/mib/git"
+	mockgit "github.com/alexandreh2ag/mib/mock/git"
+	mock_types_container "github.com/alexandreh2ag/mib/mock/types/container"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+)
+
+func TestGetBranchRunFn(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		cmdArgs   []string
+		imageData string
+		preFn     func(ctx *context.Context, ctrl *gomock.Controller)
+		checkFn   func(t *testing.T, err error)
+	}{
+		{
+			name:      "Success",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetBranchFilesChanged(gomock.Eq("develop")).Times(1).Return(
+					[]string{"foo/Dockerfile", "bar/Dockerfile"},
+					nil,
+				)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(true)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						assert.True(t, images[0].HasToBuild)
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + PushImages, "--" + Base, "develop"},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:      "ErrorCreateGitManger",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return nil, errors.New("error")
+				}
+				ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "error")
+			},
+		},
+		{
+			name:      "FailLoadImages",
+			imageData: "name: foo\ntag: ",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+				ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "configuration file is not valid")
+			},
+		},
+		{
+			name:      "ErrorGetChangedFiles",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetBranchFilesChanged(gomock.Eq("main")).Times(1).Return(nil, errors.New("error"))
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+				ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "error")
+			},
+		},
+		{
+			name:      "ErrorBuildImages",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetBranchFilesChanged(gomock.Eq("main")).Times(1).Return([]string{"foo/Dockerfile"}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).Return(errors.New("error"))
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "error")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			cmd := GetBranchCmd(ctx)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.Flags().Bool(PushImages, false, "")
+			viper.Reset()
+			viper.SetFs(ctx.FS)
+
+			_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte(tt.imageData), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+
+			tt.preFn(ctx, ctrl)
+
+			cmd.SetArgs(tt.cmdArgs)
+			err := cmd.Execute()
+			tt.checkFn(t, err)
+		})
+	}
+}
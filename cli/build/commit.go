@@ -7,10 +7,17 @@ import (
 	"github.com/alexandreh2ag/mib/git"
 	"github.com/alexandreh2ag/mib/loader"
 	"github.com/alexandreh2ag/mib/printer"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+	"path/filepath"
 )
 
-const Commit = "commit"
+const (
+	Commit       = "commit"
+	Exact        = "exact"
+	AllParents   = "all-parents"
+	PruneRemoved = "prune-removed"
+)
 
 func GetCommitCmd(ctx *context.Context) *cobra.Command {
 	cmd := &cobra.Command{
@@ -19,15 +26,22 @@ func GetCommitCmd(ctx *context.Context) *cobra.Command {
 		RunE:  GetCommitRunFn(ctx),
 	}
 
-	cmd.Flags().String(Commit, "", "Commit sha, if empty get head reference")
+	cmd.Flags().String(Commit, "", "Commit revision (sha, short sha, tag, branch or like HEAD~1), if empty get head reference")
+	cmd.Flags().Bool(AllParents, false, "Compare a merge commit with all its parents instead of the first one")
+	cmd.Flags().Bool(Exact, false, "Build images from the content of the commit instead of the working tree")
+	cmd.Flags().Bool(PruneRemoved, false, "Untag from the local daemon images deleted or renamed by the commit once the build succeed, tags published in a registry are kept")
 
 	return cmd
 }
 
 func GetCommitRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool(DryRun)
 		pushImages, _ := cmd.Flags().GetBool(PushImages)
 		commitHash, _ := cmd.Flags().GetString(Commit)
+		exact, _ := cmd.Flags().GetBool(Exact)
+		allParents, _ := cmd.Flags().GetBool(AllParents)
+		pruneRemoved, _ := cmd.Flags().GetBool(PruneRemoved)
 
 		builder := ctx.Builders.GetInstance(docker.KeyBuilder)
 		gitManager, errCreateGit := git.CreateGit(ctx)
@@ -43,26 +57,73 @@ func GetCommitRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
 			commitHash = hash
 		}
 
-		images, err := loader.LoadImages(ctx)
+		loadCtx := ctx
+		if exact {
+			exportDir, errTempDir := afero.TempDir(ctx.FS, "", "mib-")
+			if errTempDir != nil {
+				return fmt.Errorf("fail to create export dir: %v", errTempDir)
+			}
+			defer func() { _ = ctx.FS.RemoveAll(exportDir) }()
+			errExport := gitManager.ExportCommit(commitHash, ctx.FS, exportDir)
+			if errExport != nil {
+				return fmt.Errorf("fail to export commit %s: %v", commitHash, errExport)
+			}
+			ctx.Logger.Info(fmt.Sprintf("Content of commit %s exported in %s", commitHash, exportDir))
+			workingDirRel, errRel := filepath.Rel(gitManager.RootDir(), ctx.WorkingDir)
+			if errRel != nil {
+				return errRel
+			}
+			exportCtx := *ctx
+			exportCtx.WorkingDir = filepath.Join(exportDir, workingDirRel)
+			exportCtx.RepoDir = exportDir
+			loadCtx = &exportCtx
+		}
+
+		images, err := loader.LoadImages(loadCtx)
 		if err != nil {
 			return err
 		}
-		filesChanged, errGetChanged := gitManager.GetCommitFilesChanged(commitHash)
+		filesChanged, errGetChanged := gitManager.GetCommitFilesChanged(commitHash, allParents)
 		if errGetChanged != nil {
 			return errGetChanged
 		}
 
-		images.FlagChanged(loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
+		images.FlagChanged(loader.RemoveExtExcludePath(loadCtx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
+
+		imagesRemoved, errRemoved := gitManager.GetCommitImagesRemoved(commitHash, allParents)
+		if errRemoved != nil {
+			return errRemoved
+		}
 
 		if len(images) > 0 {
 			cmd.Println(printer.DisplayImagesTree(images))
 		}
+		if len(imagesRemoved) > 0 {
+			cmd.Println(printer.DisplayImagesRemovedTree(imagesRemoved))
+		}
+
+		if dryRun {
+			var remove []string
+			if pruneRemoved {
+				remove = imagesRemoved.GetNamesToPrune(images)
+			}
+			return printBuildPlan(cmd, builder, images, pushImages, remove)
+		}
 
 		errBuild := builder.BuildImages(images, pushImages)
+		printBuildSummary(cmd, images)
+		saveBuildState(ctx, builder, gitManager, images, commitHash)
 		if errBuild != nil {
 			return errBuild
 		}
 
+		if pruneRemoved {
+			errPrune := builder.RemoveImages(imagesRemoved.GetNamesToPrune(images))
+			if errPrune != nil {
+				return errPrune
+			}
+		}
+
 		return nil
 	}
 }
@@ -1,17 +1,20 @@
 package build
 
 import (
+	"bytes"
 	"errors"
 	"github.com/alexandreh2ag/mib/container/docker"
 	"github.com/alexandreh2ag/mib/context"
 	mibGit "github.com/alexandreh2ag/mib/git"
 	mockgit "github.com/alexandreh2ag/mib/mock/git"
 	mock_types_container "github.com/alexandreh2ag/mib/mock/types/container"
+	"github.com/alexandreh2ag/mib/types"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"io"
+	"path/filepath"
 	"testing"
 )
 
@@ -29,10 +32,11 @@ func TestGetCommitRunFn(t *testing.T) {
 			imageData: "name: foo\ntag: 0.1",
 			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
 				m := mockgit.NewMockManager(ctrl)
-				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx")).Times(1).Return(
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(
 					[]string{"foo/Dockerfile"},
 					nil,
 				)
+				m.EXPECT().GetCommitImagesRemoved(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(types.ImagesRemoved{}, nil)
 				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
 					return m, nil
 				}
@@ -49,6 +53,126 @@ func TestGetCommitRunFn(t *testing.T) {
 				assert.NoError(t, err)
 			},
 		},
+		{
+			name:      "SuccessExact",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().RootDir().Times(1).Return("/app")
+				m.EXPECT().ExportCommit(gomock.Eq("xxx"), gomock.Eq(ctx.FS), gomock.Any()).Times(1).DoAndReturn(
+					func(hash string, fs afero.Fs, dir string) error {
+						_ = afero.WriteFile(fs, filepath.Join(dir, "bar/mib.yml"), []byte("name: bar\ntag: 0.1"), 0644)
+						_ = afero.WriteFile(fs, filepath.Join(dir, "bar/Dockerfile"), []byte("FROM debian:latest"), 0644)
+						return nil
+					},
+				)
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(
+					[]string{"bar/Dockerfile"},
+					nil,
+				)
+				m.EXPECT().GetCommitImagesRemoved(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(types.ImagesRemoved{}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						assert.Len(t, images, 1)
+						assert.Equal(t, "bar", images[0].Name)
+						assert.True(t, images[0].HasToBuild)
+						assert.NotContains(t, images[0].Path, "/app/")
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + Commit, "xxx", "--" + Exact},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:      "SuccessExactSubdir",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().RootDir().Times(1).Return("/")
+				m.EXPECT().ExportCommit(gomock.Eq("xxx"), gomock.Eq(ctx.FS), gomock.Any()).Times(1).DoAndReturn(
+					func(hash string, fs afero.Fs, dir string) error {
+						_ = afero.WriteFile(fs, filepath.Join(dir, "app/bar/mib.yml"), []byte("name: bar\ntag: 0.1"), 0644)
+						_ = afero.WriteFile(fs, filepath.Join(dir, "app/bar/Dockerfile"), []byte("FROM debian:latest"), 0644)
+						return nil
+					},
+				)
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(
+					[]string{"bar/Dockerfile"},
+					nil,
+				)
+				m.EXPECT().GetCommitImagesRemoved(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(types.ImagesRemoved{}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						assert.Len(t, images, 1)
+						assert.Equal(t, "bar", images[0].Name)
+						assert.True(t, images[0].HasToBuild)
+						assert.Contains(t, images[0].Path, "/app/bar")
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + Commit, "xxx", "--" + Exact},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:      "ErrorExport",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().ExportCommit(gomock.Eq("xxx"), gomock.Any(), gomock.Any()).Times(1).Return(errors.New("error"))
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + Commit, "xxx", "--" + Exact},
+			checkFn: func(t *testing.T, err error) {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "fail to export commit xxx: error")
+			},
+		},
+		{
+			name:      "SuccessAllParents",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("v1.0"), gomock.Eq(true)).Times(1).Return(
+					[]string{"foo/Dockerfile"},
+					nil,
+				)
+				m.EXPECT().GetCommitImagesRemoved(gomock.Eq("v1.0"), gomock.Eq(true)).Times(1).Return(types.ImagesRemoved{}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).Return(nil)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + Commit, "v1.0", "--" + AllParents},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
 		{
 			name:      "SuccessWithoutCommitFlag",
 			imageData: "name: foo\ntag: 0.1",
@@ -60,10 +184,11 @@ func TestGetCommitRunFn(t *testing.T) {
 				}
 				gomock.InOrder(
 					m.EXPECT().Head().Times(1).Return("xxx", nil),
-					m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx")).Times(1).Return(
+					m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(
 						[]string{"foo/Dockerfile"},
 						nil,
 					),
+					m.EXPECT().GetCommitImagesRemoved(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(types.ImagesRemoved{}, nil),
 				)
 
 				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
@@ -135,7 +260,7 @@ func TestGetCommitRunFn(t *testing.T) {
 			imageData: "name: foo\ntag: 0.1",
 			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
 				m := mockgit.NewMockManager(ctrl)
-				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx")).Times(1).Return(
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(
 					nil,
 					errors.New("error"),
 				)
@@ -152,15 +277,93 @@ func TestGetCommitRunFn(t *testing.T) {
 				assert.Contains(t, err.Error(), "error")
 			},
 		},
+		{
+			name:      "SuccessPruneRemoved",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(
+					[]string{"bar/mib.yml", "foo/mib.yml"},
+					nil,
+				)
+				m.EXPECT().GetCommitImagesRemoved(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(types.ImagesRemoved{
+					{Names: []string{"bar:0.1"}, Path: "bar"},
+					{Names: []string{"foo:0.1"}, Path: "old/foo", NewPath: "foo"},
+				}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				gomock.InOrder(
+					builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).Return(nil),
+					builderDocker.EXPECT().RemoveImages(gomock.Eq([]string{"bar:0.1"})).Times(1).Return(nil),
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + Commit, "xxx", "--" + PruneRemoved},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:      "ErrorPruneRemoved",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(
+					[]string{"bar/mib.yml"},
+					nil,
+				)
+				m.EXPECT().GetCommitImagesRemoved(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(types.ImagesRemoved{
+					{Names: []string{"bar:0.1"}, Path: "bar"},
+				}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).Return(nil)
+				builderDocker.EXPECT().RemoveImages(gomock.Eq([]string{"bar:0.1"})).Times(1).Return(errors.New("error"))
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + Commit, "xxx", "--" + PruneRemoved},
+			checkFn: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
+		{
+			name:      "ErrorGetImagesRemoved",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(
+					[]string{"foo/Dockerfile"},
+					nil,
+				)
+				m.EXPECT().GetCommitImagesRemoved(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(nil, errors.New("error"))
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + Commit, "xxx"},
+			checkFn: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
 		{
 			name:      "ErrorBuildImages",
 			imageData: "name: foo\ntag: 0.1",
 			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
 				m := mockgit.NewMockManager(ctrl)
-				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx")).Times(1).Return(
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(
 					[]string{"foo/Dockerfile"},
 					nil,
 				)
+				m.EXPECT().GetCommitImagesRemoved(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(types.ImagesRemoved{}, nil)
 				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
 					return m, nil
 				}
@@ -200,3 +403,39 @@ func TestGetCommitRunFn(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCommitRunFn_SuccessDryRunPruneRemoved(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockgit.NewMockManager(ctrl)
+	m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return([]string{"bar/mib.yml", "foo/Dockerfile"}, nil)
+	m.EXPECT().GetCommitImagesRemoved(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(types.ImagesRemoved{
+		{Names: []string{"bar:0.1"}, Path: "bar"},
+	}, nil)
+	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+		return m, nil
+	}
+	builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+	builderDocker.EXPECT().GetBuildCommand(gomock.Any(), gomock.Eq(false)).Times(1).Return([]string{"docker", "build", "."}, nil)
+	ctx.Builders[docker.KeyBuilder] = builderDocker
+
+	b := bytes.NewBufferString("")
+	cmd := GetCommitCmd(ctx)
+	cmd.SetOut(b)
+	cmd.SetErr(io.Discard)
+	cmd.Flags().Bool(PushImages, false, "")
+	cmd.Flags().Bool(DryRun, false, "")
+	cmd.Flags().String(Output, OutputJson, "")
+	viper.Reset()
+	viper.SetFs(ctx.FS)
+	_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+
+	cmd.SetArgs([]string{"--" + Commit, "xxx", "--" + PruneRemoved, "--" + DryRun})
+	err := cmd.Execute()
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), "\"dir\": \"/app/foo\"")
+	assert.Contains(t, b.String(), "\"remove\": [\n    \"bar:0.1\"\n  ]")
+}
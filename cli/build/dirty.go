@@ -20,7 +20,7 @@ func GetDirtyCmd(ctx *context.Context) *cobra.Command {
 
 func GetDirtyRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		_, _ = cmd.Flags().GetBool(DryRun)
+		dryRun, _ := cmd.Flags().GetBool(DryRun)
 		pushImages, _ := cmd.Flags().GetBool(PushImages)
 		builder := ctx.Builders.GetInstance(docker.KeyBuilder)
 		gitManager, errGit := git.CreateGit(ctx)
@@ -31,12 +31,20 @@ func GetDirtyRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
 		if err != nil {
 			return err
 		}
-		filesChanged := git.GetStageFilesChanged(gitManager)
+		filesChanged, errChanged := git.GetDirtyFilesChanged(gitManager)
+		if errChanged != nil {
+			return errChanged
+		}
 		images.FlagChanged(loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
 		if len(images) > 0 {
 			cmd.Println(printer.DisplayImagesTree(images))
 		}
+		if dryRun {
+			return printBuildPlan(cmd, builder, images, pushImages, nil)
+		}
+
 		errBuild := builder.BuildImages(images, pushImages)
+		printBuildSummary(cmd, images)
 		if errBuild != nil {
 			return errBuild
 		}
@@ -7,6 +7,7 @@ import (
 	mibGit "github.com/alexandreh2ag/mib/git"
 	mockgit "github.com/alexandreh2ag/mib/mock/git"
 	mock_types_container "github.com/alexandreh2ag/mib/mock/types/container"
+	"github.com/alexandreh2ag/mib/types"
 	"github.com/go-git/go-git/v5"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
@@ -36,6 +37,7 @@ func TestGetDirtyRunFn(t *testing.T) {
 					},
 					nil,
 				)
+				m.EXPECT().GetSubmodulesFilesChanged().Times(1).Return([]string{}, nil)
 				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
 					return m, nil
 				}
@@ -49,6 +51,31 @@ func TestGetDirtyRunFn(t *testing.T) {
 				assert.NoError(t, err)
 			},
 		},
+		{
+			name:      "SuccessDryRun",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().Status().Times(1).Return(
+					git.Status{
+						"foo/Dockerfile": &git.FileStatus{Worktree: git.Unmodified, Staging: git.Modified},
+					},
+					nil,
+				)
+				m.EXPECT().GetSubmodulesFilesChanged().Times(1).Return([]string{}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().GetBuildCommand(gomock.Any(), gomock.Eq(true)).Times(1).Return([]string{"docker", "build", "."}, nil)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + PushImages, "--" + DryRun},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
 		{
 			name:      "ErrorCreateGitManger",
 			imageData: "name: foo\ntag: 0.1",
@@ -84,6 +111,50 @@ func TestGetDirtyRunFn(t *testing.T) {
 				assert.Contains(t, err.Error(), "configuration file is not valid")
 			},
 		},
+		{
+			name:      "SuccessSubmoduleChanged",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().Status().Times(1).Return(git.Status{}, nil)
+				m.EXPECT().GetSubmodulesFilesChanged().Times(1).Return([]string{"foo/shared/style.css"}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						assert.True(t, images[0].HasToBuild)
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:      "ErrorSubmodulesFilesChanged",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().Status().Times(1).Return(git.Status{}, nil)
+				m.EXPECT().GetSubmodulesFilesChanged().Times(1).Return(nil, errors.New("error"))
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
 		{
 			name:      "ErrorBuildImages",
 			imageData: "name: foo\ntag: 0.1",
@@ -95,6 +166,7 @@ func TestGetDirtyRunFn(t *testing.T) {
 					},
 					nil,
 				)
+				m.EXPECT().GetSubmodulesFilesChanged().Times(1).Return([]string{}, nil)
 				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
 					return m, nil
 				}
@@ -119,6 +191,7 @@ func TestGetDirtyRunFn(t *testing.T) {
 			cmd.SetOut(io.Discard)
 			cmd.SetErr(io.Discard)
 			cmd.Flags().Bool(PushImages, false, "")
+			cmd.Flags().Bool(DryRun, false, "")
 			viper.Reset()
 			viper.SetFs(ctx.FS)
 
@@ -1,6 +1,23 @@
 package build
 
+import "github.com/spf13/cobra"
+
 const (
 	PushImages = "push"
 	DryRun     = "dry-run"
+	Base       = "base"
+	Jobs       = "jobs"
+	KeepGoing  = "keep-going"
+	Output     = "output"
+
+	OutputText = "text"
+	OutputJson = "json"
+
+	DefaultBase = "main"
 )
+
+// AddBaseFlag adds the flag of the revision the current branch is compared with, an empty defaultBase makes the
+// comparison optional. It is shared by every command comparing a branch, like a pull request diff.
+func AddBaseFlag(cmd *cobra.Command, defaultBase string) {
+	cmd.Flags().String(Base, defaultBase, "Base revision the current branch is compared with, from the merge-base of HEAD and this revision to HEAD")
+}
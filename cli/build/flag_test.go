@@ -0,0 +1,21 @@
+package build
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAddBaseFlag(t *testing.T) {
+	cmd := &cobra.Command{}
+	AddBaseFlag(cmd, DefaultBase)
+	base, err := cmd.Flags().GetString(Base)
+	assert.NoError(t, err)
+	assert.Equal(t, "main", base)
+
+	cmd = &cobra.Command{}
+	AddBaseFlag(cmd, "")
+	base, err = cmd.Flags().GetString(Base)
+	assert.NoError(t, err)
+	assert.Equal(t, "", base)
+}
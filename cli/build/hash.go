@@ -0,0 +1,104 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/cobra"
+)
+
+func GetHashCmd(ctx *context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "hash",
+		Short: "Build image with content hash different from the published one",
+		Long:  "Build each image whose content hash, computed from its Dockerfile, build context, build args and parent, differs from the " + types.LabelContentHash + " label of the image published in the registry. It does not rely on git. With --dry-run the registry is not queried, the content hash of every image is printed to be checked against the published one.",
+		RunE:  GetHashRunFn(ctx),
+	}
+}
+
+func GetHashRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool(DryRun)
+		pushImages, _ := cmd.Flags().GetBool(PushImages)
+		builder := ctx.Builders.GetInstance(docker.KeyBuilder)
+
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			return printContentHashes(cmd, ctx, images)
+		}
+
+		errFlag := images.FlagHashChanged(func(image *types.Image) (bool, error) {
+			contentHash, errHash := loader.ComputeContentHash(ctx, image)
+			if errHash != nil {
+				return false, errHash
+			}
+			publishedHash, errPublished := builder.GetPublishedLabel(image.GetFullName(), types.LabelContentHash)
+			if errPublished != nil {
+				ctx.Logger.Warn(fmt.Sprintf("fail to get published content hash of %s, it will be rebuilt: %v", image.GetFullName(), errPublished))
+				return true, nil
+			}
+			ctx.Logger.Debug(fmt.Sprintf("content hash of %s is %s, published one is %s", image.GetFullName(), contentHash, publishedHash))
+			return publishedHash != contentHash, nil
+		})
+		if errFlag != nil {
+			return errFlag
+		}
+
+		if len(images) > 0 {
+			cmd.Println(printer.DisplayImagesTree(images))
+		}
+
+		errBuild := builder.BuildImages(images, pushImages)
+		printBuildSummary(cmd, images)
+		saveBuildStateAtHead(ctx, builder, images)
+		if errBuild != nil {
+			return errBuild
+		}
+
+		return nil
+	}
+}
+
+// hashPlanImage is an image whose published content hash would be checked by `build hash`.
+type hashPlanImage struct {
+	Name        string `json:"name"`
+	ContentHash string `json:"contentHash"`
+}
+
+// printContentHashes prints on stdout the content hash of every image, as text or JSON, without querying the registry
+// so a dry run does not need credentials. Whether an image would be built is unknown until its published label is checked.
+func printContentHashes(cmd *cobra.Command, ctx *context.Context, images types.Images) error {
+	output, _ := cmd.Flags().GetString(Output)
+	if output == "" {
+		output = OutputText
+	}
+	if output != OutputText && output != OutputJson {
+		return fmt.Errorf("output %s is not supported, use %s or %s", output, OutputText, OutputJson)
+	}
+
+	plan := []hashPlanImage{}
+	for _, image := range images.GetAll() {
+		contentHash, err := loader.ComputeContentHash(ctx, image)
+		if err != nil {
+			return err
+		}
+		plan = append(plan, hashPlanImage{Name: image.GetFullName(), ContentHash: contentHash})
+	}
+	if output == OutputJson {
+		content, _ := json.MarshalIndent(plan, "", "  ")
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(content))
+		return nil
+	}
+	for _, image := range plan {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s %s (would check the published %s label)\n", image.Name, image.ContentHash, types.LabelContentHash)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package build
+
+import (
+	"bytes"
+	"errors"
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/loader"
+	mock_types_container "github.com/alexandreh2ag/mib/mock/types/container"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+)
+
+func TestGetHashRunFn(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmdArgs []string
+		preFn   func(ctx *context.Context, ctrl *gomock.Controller)
+		checkFn func(t *testing.T, err error)
+	}{
+		{
+			name: "SuccessHashChanged",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				barHash, _ := loader.ComputeContentHash(ctx, &types.Image{ImageName: types.ImageName{Name: "bar", Tag: "0.1"}, Path: "/app/bar", RelativeDir: "bar", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}})
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().GetPublishedLabel(gomock.Eq("foo:0.1"), gomock.Eq(types.LabelContentHash)).Times(1).Return("sha256:old", nil)
+				builderDocker.EXPECT().GetPublishedLabel(gomock.Eq("bar:0.1"), gomock.Eq(types.LabelContentHash)).Times(1).Return(barHash, nil)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(true)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						for _, image := range images {
+							assert.Equal(t, image.Name == "foo", image.HasToBuild, image.Name)
+							assert.NotEmpty(t, image.ContentHash)
+						}
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + PushImages},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "SuccessNotPublished",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().GetPublishedLabel(gomock.Any(), gomock.Eq(types.LabelContentHash)).Times(2).Return("", errors.New("not found"))
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						for _, image := range images {
+							assert.True(t, image.HasToBuild, image.Name)
+						}
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "ErrorBuildImages",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().GetPublishedLabel(gomock.Any(), gomock.Any()).Times(2).Return("", nil)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).Return(errors.New("error"))
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			checkFn: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			cmd := GetHashCmd(ctx)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.Flags().Bool(PushImages, false, "")
+			viper.Reset()
+			viper.SetFs(ctx.FS)
+
+			_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/bar/mib.yml", []byte("name: bar\ntag: 0.1"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/bar/Dockerfile", []byte("FROM debian:latest"), 0644)
+
+			tt.preFn(ctx, ctrl)
+
+			cmd.SetArgs(tt.cmdArgs)
+			err := cmd.Execute()
+			tt.checkFn(t, err)
+		})
+	}
+}
+
+func TestGetHashRunFn_DryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		checkFn func(t *testing.T, fooHash string, out string, err error)
+	}{
+		{
+			name:   "SuccessText",
+			output: OutputText,
+			checkFn: func(t *testing.T, fooHash string, out string, err error) {
+				assert.NoError(t, err)
+				assert.Contains(t, out, "foo:0.1 "+fooHash+" (would check the published "+types.LabelContentHash+" label)\n")
+				assert.Contains(t, out, "bar:0.1 sha256:")
+			},
+		},
+		{
+			name:   "SuccessJson",
+			output: OutputJson,
+			checkFn: func(t *testing.T, fooHash string, out string, err error) {
+				assert.NoError(t, err)
+				assert.Contains(t, out, "\"name\": \"foo:0.1\",\n    \"contentHash\": \""+fooHash+"\"")
+			},
+		},
+		{
+			name:   "ErrorOutput",
+			output: "yaml",
+			checkFn: func(t *testing.T, fooHash string, out string, err error) {
+				assert.EqualError(t, err, "output yaml is not supported, use text or json")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			// no expectation, the registry must not be queried in dry run
+			ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			out := &bytes.Buffer{}
+			cmd := GetHashCmd(ctx)
+			cmd.SetOut(out)
+			cmd.SetErr(io.Discard)
+			cmd.Flags().Bool(PushImages, false, "")
+			cmd.Flags().Bool(DryRun, false, "")
+			cmd.Flags().String(Output, "", "")
+			viper.Reset()
+			viper.SetFs(ctx.FS)
+
+			_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/bar/mib.yml", []byte("name: bar\ntag: 0.1"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/bar/Dockerfile", []byte("FROM debian:latest"), 0644)
+			fooHash, _ := loader.ComputeContentHash(ctx, &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", RelativeDir: "foo", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}})
+
+			cmd.SetArgs([]string{"--" + DryRun, "--" + Output, tt.output})
+			err := cmd.Execute()
+			tt.checkFn(t, fooHash, out.String(), err)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package build
+
+import (
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/spf13/cobra"
+)
+
+const (
+	WithChildren = "with-children"
+	WithParents  = "with-parents"
+	Exclude      = "exclude"
+)
+
+func GetImageCmd(ctx *context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "image <name[:tag]|path>...",
+		Short: "Build selected images",
+		Long:  "Build images selected by name, with or without tag, or by dir, absolute or relative to the working dir. It does not rely on git.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  GetImageRunFn(ctx),
+	}
+
+	cmd.Flags().Bool(WithChildren, false, "Also build children and dependents of selected images, recursively")
+	cmd.Flags().Bool(WithParents, false, "Also build local parents and dependencies of selected images, recursively")
+	cmd.Flags().StringSlice(Exclude, []string{}, "Do not build images whose name or dir match the glob")
+
+	return cmd
+}
+
+func GetImageRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool(DryRun)
+		pushImages, _ := cmd.Flags().GetBool(PushImages)
+		withChildren, _ := cmd.Flags().GetBool(WithChildren)
+		withParents, _ := cmd.Flags().GetBool(WithParents)
+		exclude, _ := cmd.Flags().GetStringSlice(Exclude)
+		builder := ctx.Builders.GetInstance(docker.KeyBuilder)
+
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+
+		errSelect := images.FlagSelected(ctx.WorkingDir, args, withParents, withChildren)
+		if errSelect != nil {
+			return errSelect
+		}
+		errExclude := images.UnflagExcluded(exclude)
+		if errExclude != nil {
+			return errExclude
+		}
+
+		if len(images) > 0 {
+			cmd.Println(printer.DisplayImagesTree(images))
+		}
+
+		if dryRun {
+			return printBuildPlan(cmd, builder, images, pushImages, nil)
+		}
+
+		errBuild := builder.BuildImages(images, pushImages)
+		printBuildSummary(cmd, images)
+		saveBuildStateAtHead(ctx, builder, images)
+		if errBuild != nil {
+			return errBuild
+		}
+
+		return nil
+	}
+}
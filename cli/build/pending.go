@@ -0,0 +1,86 @@
+package build
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/git"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+func GetPendingCmd(ctx *context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pending",
+		Short: "Build image changed since its last successful build",
+		Long:  "Build each image with files changed between the commit of its last successful build and HEAD, or never built.",
+		RunE:  GetPendingRunFn(ctx),
+	}
+}
+
+func GetPendingRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool(DryRun)
+		pushImages, _ := cmd.Flags().GetBool(PushImages)
+
+		if ctx.StateStore == nil {
+			return errors.New("no build state store configured")
+		}
+		builder := ctx.Builders.GetInstance(docker.KeyBuilder)
+		gitManager, errCreateGit := git.CreateGit(ctx)
+		if errCreateGit != nil {
+			return errCreateGit
+		}
+
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+
+		filesSince := map[string][]string{}
+		errFlag := images.FlagChangedSince(func(image *types.Image) ([]string, bool, error) {
+			buildState, errState := ctx.StateStore.Get(image.GetFullName())
+			if errState != nil {
+				return nil, false, fmt.Errorf("fail to get build state of %s: %v", image.GetFullName(), errState)
+			}
+			if buildState == nil {
+				return nil, false, nil
+			}
+			if files, ok := filesSince[buildState.Commit]; ok {
+				return files, true, nil
+			}
+			filesChanged, errGetChanged := gitManager.GetRangeFilesChanged(buildState.Commit, string(plumbing.HEAD))
+			if errGetChanged != nil {
+				ctx.Logger.Warn(fmt.Sprintf("fail to get files changed since last build of %s, it will be rebuilt: %v", image.GetFullName(), errGetChanged))
+				return nil, false, nil
+			}
+			files := loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged)
+			filesSince[buildState.Commit] = files
+			return files, true, nil
+		})
+		if errFlag != nil {
+			return errFlag
+		}
+
+		if len(images) > 0 {
+			cmd.Println(printer.DisplayImagesTree(images))
+		}
+
+		if dryRun {
+			return printBuildPlan(cmd, builder, images, pushImages, nil)
+		}
+
+		errBuild := builder.BuildImages(images, pushImages)
+		printBuildSummary(cmd, images)
+		saveBuildState(ctx, builder, gitManager, images, string(plumbing.HEAD))
+		if errBuild != nil {
+			return errBuild
+		}
+
+		return nil
+	}
+}
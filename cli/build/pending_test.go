@@ -0,0 +1,255 @@
+package build
+
+import (
+	"errors"
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	mibGit "github.com/alexandreh2ag/mib/git"
+	mockgit "github.com/alexandreh2ag/mib/mock/git"
+	mock_types_container "github.com/alexandreh2ag/mib/mock/types/container"
+	"github.com/alexandreh2ag/mib/state/file"
+	"github.com/alexandreh2ag/mib/types"
+	typesState "github.com/alexandreh2ag/mib/types/state"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+)
+
+func TestGetPendingRunFn(t *testing.T) {
+	headHash := plumbing.NewHash("1111111111111111111111111111111111111111")
+	tests := []struct {
+		name    string
+		cmdArgs []string
+		preFn   func(ctx *context.Context, ctrl *gomock.Controller)
+		checkFn func(t *testing.T, ctx *context.Context, err error)
+	}{
+		{
+			name: "SuccessNeverBuilt",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				_ = ctx.StateStore.Save("bar:0.1", typesState.BuildState{Commit: "aaa"})
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetRangeFilesChanged(gomock.Eq("aaa"), gomock.Eq("HEAD")).Times(1).Return([]string{"foo/Dockerfile"}, nil)
+				m.EXPECT().ResolveRevision(gomock.Eq(plumbing.Revision("HEAD"))).Times(1).Return(&headHash, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(true)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						for _, image := range images {
+							assert.Equal(t, image.Name == "foo", image.HasToBuild, image.Name)
+							image.IsBuilt = image.HasToBuild
+						}
+						return nil
+					},
+				)
+				builderDocker.EXPECT().GetDigest(gomock.Eq("foo:0.1")).Times(1).Return("sha256:foo", nil)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + PushImages},
+			checkFn: func(t *testing.T, ctx *context.Context, err error) {
+				assert.NoError(t, err)
+				got, _ := ctx.StateStore.Get("foo:0.1")
+				assert.Equal(t, headHash.String(), got.Commit)
+				assert.Equal(t, "sha256:foo", got.Digest)
+				got, _ = ctx.StateStore.Get("bar:0.1")
+				assert.Equal(t, "aaa", got.Commit)
+			},
+		},
+		{
+			name: "SuccessChangedSinceLastBuild",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				_ = ctx.StateStore.Save("foo:0.1", typesState.BuildState{Commit: "aaa"})
+				_ = ctx.StateStore.Save("bar:0.1", typesState.BuildState{Commit: "bbb"})
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetRangeFilesChanged(gomock.Eq("aaa"), gomock.Eq("HEAD")).Times(1).Return([]string{"bar/Dockerfile", "foo/Dockerfile"}, nil)
+				m.EXPECT().GetRangeFilesChanged(gomock.Eq("bbb"), gomock.Eq("HEAD")).Times(1).Return([]string{"foo/Dockerfile"}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						for _, image := range images {
+							assert.Equal(t, image.Name == "foo", image.HasToBuild, image.Name)
+						}
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			checkFn: func(t *testing.T, ctx *context.Context, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "SuccessUnknownLastBuildCommit",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				_ = ctx.StateStore.Save("foo:0.1", typesState.BuildState{Commit: "aaa"})
+				_ = ctx.StateStore.Save("bar:0.1", typesState.BuildState{Commit: "aaa"})
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetRangeFilesChanged(gomock.Eq("aaa"), gomock.Eq("HEAD")).Times(2).Return(nil, errors.New("commit not found"))
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						for _, image := range images {
+							assert.True(t, image.HasToBuild, image.Name)
+						}
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			checkFn: func(t *testing.T, ctx *context.Context, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "ErrorNoStateStore",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				ctx.StateStore = nil
+			},
+			checkFn: func(t *testing.T, ctx *context.Context, err error) {
+				assert.EqualError(t, err, "no build state store configured")
+			},
+		},
+		{
+			name: "ErrorCreateGitManger",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return nil, errors.New("error")
+				}
+				ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			},
+			checkFn: func(t *testing.T, ctx *context.Context, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
+		{
+			name: "ErrorGetState",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				_ = afero.WriteFile(ctx.FS, "/app/.mib-state.json", []byte("{wrong"), 0644)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return mockgit.NewMockManager(ctrl), nil
+				}
+				ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			},
+			checkFn: func(t *testing.T, ctx *context.Context, err error) {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "fail to get build state of")
+			},
+		},
+		{
+			name: "ErrorBuildImages",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return mockgit.NewMockManager(ctrl), nil
+				}
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).Return(errors.New("error"))
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			checkFn: func(t *testing.T, ctx *context.Context, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			ctx.Config.Build.State.Path = ".mib-state.json"
+			ctx.StateStore, _ = file.CreateFileStore(ctx)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			cmd := GetPendingCmd(ctx)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.Flags().Bool(PushImages, false, "")
+			viper.Reset()
+			viper.SetFs(ctx.FS)
+
+			_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/bar/mib.yml", []byte("name: bar\ntag: 0.1"), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/bar/Dockerfile", []byte("FROM debian:latest"), 0644)
+
+			tt.preFn(ctx, ctrl)
+
+			cmd.SetArgs(tt.cmdArgs)
+			err := cmd.Execute()
+			tt.checkFn(t, ctx, err)
+		})
+	}
+}
+
+func TestGetPendingRunFn_SuccessStateSavedByAll(t *testing.T) {
+	headHash := plumbing.NewHash("1111111111111111111111111111111111111111")
+	ctx := context.TestContext(nil)
+	ctx.Config.Build.State.Path = ".mib-state.json"
+	ctx.StateStore, _ = file.CreateFileStore(ctx)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	viper.Reset()
+	viper.SetFs(ctx.FS)
+
+	_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/bar/mib.yml", []byte("name: bar\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/bar/Dockerfile", []byte("FROM debian:latest"), 0644)
+
+	m := mockgit.NewMockManager(ctrl)
+	m.EXPECT().ResolveRevision(gomock.Eq(plumbing.Revision("HEAD"))).Times(1).Return(&headHash, nil)
+	m.EXPECT().GetRangeFilesChanged(gomock.Eq(headHash.String()), gomock.Eq("HEAD")).Times(1).Return([]string{"foo/Dockerfile"}, nil)
+	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+		return m, nil
+	}
+	builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+	gomock.InOrder(
+		builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).DoAndReturn(
+			func(images types.Images, pushImages bool) error {
+				for _, image := range images {
+					image.IsBuilt = image.HasToBuild
+				}
+				return nil
+			},
+		),
+		builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).DoAndReturn(
+			func(images types.Images, pushImages bool) error {
+				for _, image := range images {
+					assert.Equal(t, image.Name == "foo", image.HasToBuild, image.Name)
+				}
+				return nil
+			},
+		),
+	)
+	builderDocker.EXPECT().GetDigest(gomock.Any()).Times(2).Return("", nil)
+	ctx.Builders[docker.KeyBuilder] = builderDocker
+
+	cmdAll := GetAllCmd(ctx)
+	cmdAll.SetOut(io.Discard)
+	cmdAll.SetErr(io.Discard)
+	cmdAll.Flags().Bool(PushImages, false, "")
+	cmdAll.Flags().Bool(DryRun, false, "")
+	cmdAll.SetArgs([]string{})
+	assert.NoError(t, cmdAll.Execute())
+
+	cmdPending := GetPendingCmd(ctx)
+	cmdPending.SetOut(io.Discard)
+	cmdPending.SetErr(io.Discard)
+	cmdPending.Flags().Bool(PushImages, false, "")
+	cmdPending.Flags().Bool(DryRun, false, "")
+	cmdPending.SetArgs([]string{})
+	assert.NoError(t, cmdPending.Execute())
+}
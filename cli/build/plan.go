@@ -0,0 +1,38 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/alexandreh2ag/mib/container"
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/alexandreh2ag/mib/types"
+	typesContainers "github.com/alexandreh2ag/mib/types/container"
+	"github.com/spf13/cobra"
+)
+
+// printBuildPlan prints on stdout the builds a dry run would have run and the local images it would have removed,
+// as text or JSON.
+func printBuildPlan(cmd *cobra.Command, builder typesContainers.BuilderImage, images types.Images, pushImages bool, remove []string) error {
+	output, _ := cmd.Flags().GetString(Output)
+	if output == "" {
+		output = OutputText
+	}
+	if output != OutputText && output != OutputJson {
+		return fmt.Errorf("output %s is not supported, use %s or %s", output, OutputText, OutputJson)
+	}
+
+	plan, err := container.GetBuildPlan(builder, images, pushImages)
+	if err != nil {
+		return fmt.Errorf("fail to get build plan with error: %v", err)
+	}
+	if remove != nil {
+		plan.Remove = remove
+	}
+	if output == OutputJson {
+		content, _ := json.MarshalIndent(plan, "", "  ")
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(content))
+		return nil
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), printer.DisplayBuildPlan(plan))
+	return nil
+}
@@ -0,0 +1,120 @@
+package build
+
+import (
+	"bytes"
+	"errors"
+	mock_types_container "github.com/alexandreh2ag/mib/mock/types/container"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func Test_printBuildPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		remove  []string
+		preFn   func(builder *mock_types_container.MockBuilderImage)
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "SuccessText",
+			output: OutputText,
+			preFn: func(builder *mock_types_container.MockBuilderImage) {
+				builder.EXPECT().GetBuildCommand(gomock.Any(), gomock.Eq(true)).Times(1).Return([]string{"docker", "build", "."}, nil)
+			},
+			want: "build plan: 1 images\n" +
+				"└── 1. foo:0.1 (foo)\n" +
+				"    ├── dir: /app/foo\n" +
+				"    ├── tags: foo:0.1\n" +
+				"    ├── command: docker build .\n" +
+				"    └── push: foo:0.1\n\n",
+		},
+		{
+			name:   "SuccessTextRemove",
+			output: OutputText,
+			remove: []string{"old:0.1"},
+			preFn: func(builder *mock_types_container.MockBuilderImage) {
+				builder.EXPECT().GetBuildCommand(gomock.Any(), gomock.Eq(true)).Times(1).Return([]string{"docker", "build", "."}, nil)
+			},
+			want: "build plan: 1 images\n" +
+				"├── 1. foo:0.1 (foo)\n" +
+				"│   ├── dir: /app/foo\n" +
+				"│   ├── tags: foo:0.1\n" +
+				"│   ├── command: docker build .\n" +
+				"│   └── push: foo:0.1\n" +
+				"└── remove local images: old:0.1\n\n",
+		},
+		{
+			name:   "SuccessJson",
+			output: OutputJson,
+			preFn: func(builder *mock_types_container.MockBuilderImage) {
+				builder.EXPECT().GetBuildCommand(gomock.Any(), gomock.Eq(true)).Times(1).Return([]string{"docker", "build", "."}, nil)
+			},
+			want: `{
+  "builds": [
+    {
+      "name": "foo:0.1",
+      "path": "foo",
+      "dir": "/app/foo",
+      "tags": [
+        "foo:0.1"
+      ],
+      "platforms": [],
+      "command": [
+        "docker",
+        "build",
+        "."
+      ],
+      "push": [
+        "foo:0.1"
+      ]
+    }
+  ],
+  "remove": []
+}
+`,
+		},
+		{
+			name:    "ErrorOutput",
+			output:  "yaml",
+			preFn:   func(builder *mock_types_container.MockBuilderImage) {},
+			wantErr: "output yaml is not supported, use text or json",
+		},
+		{
+			name:   "ErrorGetBuildCommand",
+			output: OutputText,
+			preFn: func(builder *mock_types_container.MockBuilderImage) {
+				builder.EXPECT().GetBuildCommand(gomock.Any(), gomock.Eq(true)).Times(1).Return(nil, errors.New("error"))
+			},
+			wantErr: "fail to get build plan with error: error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			b := bytes.NewBufferString("")
+			cmd := &cobra.Command{}
+			cmd.SetOut(b)
+			cmd.Flags().String(Output, tt.output, "")
+			builder := mock_types_container.NewMockBuilderImage(ctrl)
+			tt.preFn(builder)
+			images := types.Images{
+				&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", RelativeDir: "foo", HasToBuild: true},
+				&types.Image{ImageName: types.ImageName{Name: "bar", Tag: "0.1"}, Path: "/app/bar", RelativeDir: "bar"},
+			}
+
+			err := printBuildPlan(cmd, builder, images, true, tt.remove)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package build
+
+import (
+	"errors"
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/git"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+const RangeSeparator = ".."
+
+func GetRangeCmd(ctx *context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "range <from>..<to>",
+		Short: "Build image for all commits of a range",
+		Long:  "Build image for all commits reachable from <to> but not from <from>, revisions can be commit sha, tags, branches or like HEAD~3. An empty revision means HEAD.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  GetRangeRunFn(ctx),
+	}
+}
+
+func ParseRange(revisionRange string) (string, string, error) {
+	from, to, found := strings.Cut(revisionRange, RangeSeparator)
+	if !found || strings.HasPrefix(to, ".") {
+		return "", "", errors.New("range must be formatted like <from>..<to>")
+	}
+	if from == "" {
+		from = "HEAD"
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+	return from, to, nil
+}
+
+func GetRangeRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool(DryRun)
+		pushImages, _ := cmd.Flags().GetBool(PushImages)
+		from, to, errRange := ParseRange(args[0])
+		if errRange != nil {
+			return errRange
+		}
+
+		builder := ctx.Builders.GetInstance(docker.KeyBuilder)
+		gitManager, errCreateGit := git.CreateGit(ctx)
+		if errCreateGit != nil {
+			return errCreateGit
+		}
+
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+		filesChanged, errGetChanged := gitManager.GetRangeFilesChanged(from, to)
+		if errGetChanged != nil {
+			return errGetChanged
+		}
+
+		images.FlagChanged(loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
+
+		if len(images) > 0 {
+			cmd.Println(printer.DisplayImagesTree(images))
+		}
+
+		if dryRun {
+			return printBuildPlan(cmd, builder, images, pushImages, nil)
+		}
+
+		errBuild := builder.BuildImages(images, pushImages)
+		printBuildSummary(cmd, images)
+		saveBuildState(ctx, builder, gitManager, images, to)
+		if errBuild != nil {
+			return errBuild
+		}
+
+		return nil
+	}
+}
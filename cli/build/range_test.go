@@ -0,0 +1,180 @@
+package build
+
+import (
+	"errors"
+	"github.com/alexandreh2ag/mib/container/docker"
+	"github.com/alexandreh2ag/mib/context"
+	mibGit "github.com/alexandreh2ag/mib/git"
+	mockgit "github.com/alexandreh2ag/mib/mock/git"
+	mock_types_container "github.com/alexandreh2ag/mib/mock/types/container"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		wantFrom string
+		wantTo   string
+		wantErr  bool
+	}{
+		{name: "Success", value: "v1.0..v1.1", wantFrom: "v1.0", wantTo: "v1.1"},
+		{name: "SuccessRelative", value: "HEAD~3..HEAD", wantFrom: "HEAD~3", wantTo: "HEAD"},
+		{name: "SuccessEmptyTo", value: "origin/main..", wantFrom: "origin/main", wantTo: "HEAD"},
+		{name: "SuccessEmptyFrom", value: "..feature", wantFrom: "HEAD", wantTo: "feature"},
+		{name: "FailNoSeparator", value: "HEAD", wantErr: true},
+		{name: "FailSymmetricDifference", value: "main...feature", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := ParseRange(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFrom, from)
+			assert.Equal(t, tt.wantTo, to)
+		})
+	}
+}
+
+func TestGetRangeRunFn(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		cmdArgs   []string
+		imageData string
+		preFn     func(ctx *context.Context, ctrl *gomock.Controller)
+		checkFn   func(t *testing.T, err error)
+	}{
+		{
+			name:      "Success",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetRangeFilesChanged(gomock.Eq("v1"), gomock.Eq("HEAD")).Times(1).Return(
+					[]string{"foo/Dockerfile", "bar/Dockerfile"},
+					nil,
+				)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(true)).Times(1).DoAndReturn(
+					func(images types.Images, pushImages bool) error {
+						assert.True(t, images[0].HasToBuild)
+						return nil
+					},
+				)
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"--" + PushImages, "v1.."},
+			checkFn: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:      "ErrorInvalidRange",
+			imageData: "name: foo\ntag: 0.1",
+			preFn:     func(ctx *context.Context, ctrl *gomock.Controller) {},
+			cmdArgs:   []string{"v1"},
+			checkFn: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "range must be formatted like <from>..<to>")
+			},
+		},
+		{
+			name:      "ErrorCreateGitManger",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return nil, errors.New("error")
+				}
+				ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			},
+			cmdArgs: []string{"v1..v2"},
+			checkFn: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "error")
+			},
+		},
+		{
+			name:      "FailLoadImages",
+			imageData: "name: foo\ntag: ",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+				ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			},
+			cmdArgs: []string{"v1..v2"},
+			checkFn: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "configuration file is not valid")
+			},
+		},
+		{
+			name:      "ErrorGetChangedFiles",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetRangeFilesChanged(gomock.Eq("v1"), gomock.Eq("v2")).Times(1).Return(nil, errors.New("error"))
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+				ctx.Builders[docker.KeyBuilder] = mock_types_container.NewMockBuilderImage(ctrl)
+			},
+			cmdArgs: []string{"v1..v2"},
+			checkFn: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "error")
+			},
+		},
+		{
+			name:      "ErrorBuildImages",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetRangeFilesChanged(gomock.Eq("v1"), gomock.Eq("v2")).Times(1).Return([]string{"foo/Dockerfile"}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+				builderDocker := mock_types_container.NewMockBuilderImage(ctrl)
+				builderDocker.EXPECT().BuildImages(gomock.Any(), gomock.Eq(false)).Times(1).Return(errors.New("error"))
+				ctx.Builders[docker.KeyBuilder] = builderDocker
+			},
+			cmdArgs: []string{"v1..v2"},
+			checkFn: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "error")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			cmd := GetRangeCmd(ctx)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			cmd.Flags().Bool(PushImages, false, "")
+			viper.Reset()
+			viper.SetFs(ctx.FS)
+
+			_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte(tt.imageData), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+
+			tt.preFn(ctx, ctrl)
+
+			cmd.SetArgs(tt.cmdArgs)
+			err := cmd.Execute()
+			tt.checkFn(t, err)
+		})
+	}
+}
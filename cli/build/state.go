@@ -0,0 +1,55 @@
+package build
+
+import (
+	"fmt"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/git"
+	"github.com/alexandreh2ag/mib/types"
+	typesContainers "github.com/alexandreh2ag/mib/types/container"
+	typesState "github.com/alexandreh2ag/mib/types/state"
+	"github.com/go-git/go-git/v5/plumbing"
+	"time"
+)
+
+// saveBuildState records the images built successfully at the revision, so `build pending` starts from there.
+// A failure is only logged since the images are already built.
+func saveBuildState(ctx *context.Context, builder typesContainers.BuilderImage, gitManager git.Manager, images types.Images, revision string) {
+	if ctx.StateStore == nil {
+		return
+	}
+	commit := ""
+	for _, image := range images.GetAll() {
+		if !image.IsBuilt {
+			continue
+		}
+		if commit == "" {
+			hash, err := gitManager.ResolveRevision(plumbing.Revision(revision))
+			if err != nil {
+				ctx.Logger.Warn(fmt.Sprintf("fail to save build state, revision %s not found: %v", revision, err))
+				return
+			}
+			commit = hash.String()
+		}
+		digest, errDigest := builder.GetDigest(image.GetFullName())
+		if errDigest != nil {
+			ctx.Logger.Debug(fmt.Sprintf("fail to get digest of %s: %v", image.GetFullName(), errDigest))
+		}
+		errSave := ctx.StateStore.Save(image.GetFullName(), typesState.BuildState{Commit: commit, Digest: digest, BuiltAt: time.Now()})
+		if errSave != nil {
+			ctx.Logger.Warn(fmt.Sprintf("fail to save build state of %s: %v", image.GetFullName(), errSave))
+		}
+	}
+}
+
+// saveBuildStateAtHead records the images built by a mode not relying on git at HEAD, when the working dir is in a git repository.
+func saveBuildStateAtHead(ctx *context.Context, builder typesContainers.BuilderImage, images types.Images) {
+	if ctx.StateStore == nil {
+		return
+	}
+	gitManager, err := git.CreateGit(ctx)
+	if err != nil {
+		ctx.Logger.Debug(fmt.Sprintf("build state not saved, no git repository found: %v", err))
+		return
+	}
+	saveBuildState(ctx, builder, gitManager, images, string(plumbing.HEAD))
+}
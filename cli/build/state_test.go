@@ -0,0 +1,90 @@
+package build
+
+import (
+	"bytes"
+	"errors"
+	"github.com/alexandreh2ag/mib/context"
+	mibGit "github.com/alexandreh2ag/mib/git"
+	mockgit "github.com/alexandreh2ag/mib/mock/git"
+	mock_types_container "github.com/alexandreh2ag/mib/mock/types/container"
+	"github.com/alexandreh2ag/mib/state/file"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func Test_saveBuildState_Success(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.Config.Build.State.Path = ".mib-state.json"
+	ctx.StateStore, _ = file.CreateFileStore(ctx)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	hash := plumbing.NewHash("1111111111111111111111111111111111111111")
+	child := &types.Image{ImageName: types.ImageName{Name: "bar", Tag: "0.1"}, IsBuilt: true}
+	images := types.Images{
+		&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, IsBuilt: true, Children: types.Images{child}},
+		&types.Image{ImageName: types.ImageName{Name: "baz", Tag: "0.1"}},
+	}
+	m := mockgit.NewMockManager(ctrl)
+	m.EXPECT().ResolveRevision(gomock.Eq(plumbing.Revision("v1"))).Times(1).Return(&hash, nil)
+	builder := mock_types_container.NewMockBuilderImage(ctrl)
+	builder.EXPECT().GetDigest(gomock.Eq("foo:0.1")).Times(1).Return("sha256:foo", nil)
+	builder.EXPECT().GetDigest(gomock.Eq("bar:0.1")).Times(1).Return("", errors.New("error"))
+
+	saveBuildState(ctx, builder, m, images, "v1")
+
+	got, _ := ctx.StateStore.Get("foo:0.1")
+	assert.Equal(t, hash.String(), got.Commit)
+	assert.Equal(t, "sha256:foo", got.Digest)
+	got, _ = ctx.StateStore.Get("bar:0.1")
+	assert.Equal(t, hash.String(), got.Commit)
+	assert.Equal(t, "", got.Digest)
+	got, _ = ctx.StateStore.Get("baz:0.1")
+	assert.Nil(t, got)
+}
+
+func Test_saveBuildState_SuccessWithoutStore(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	images := types.Images{&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, IsBuilt: true}}
+
+	saveBuildState(ctx, mock_types_container.NewMockBuilderImage(ctrl), mockgit.NewMockManager(ctrl), images, "v1")
+}
+
+func Test_saveBuildState_ErrorResolveRevision(t *testing.T) {
+	b := bytes.NewBufferString("")
+	ctx := context.TestContext(b)
+	ctx.Config.Build.State.Path = ".mib-state.json"
+	ctx.StateStore, _ = file.CreateFileStore(ctx)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	images := types.Images{&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, IsBuilt: true}}
+	m := mockgit.NewMockManager(ctrl)
+	m.EXPECT().ResolveRevision(gomock.Eq(plumbing.Revision("v1"))).Times(1).Return(nil, errors.New("error"))
+
+	saveBuildState(ctx, mock_types_container.NewMockBuilderImage(ctrl), m, images, "v1")
+
+	assert.Contains(t, b.String(), "fail to save build state, revision v1 not found: error")
+	got, _ := ctx.StateStore.Get("foo:0.1")
+	assert.Nil(t, got)
+}
+
+func Test_saveBuildStateAtHead_SuccessWithoutGit(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.Config.Build.State.Path = ".mib-state.json"
+	ctx.StateStore, _ = file.CreateFileStore(ctx)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	images := types.Images{&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, IsBuilt: true}}
+	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+		return nil, errors.New("repository does not exist")
+	}
+
+	saveBuildStateAtHead(ctx, mock_types_container.NewMockBuilderImage(ctrl), images)
+
+	got, _ := ctx.StateStore.Get("foo:0.1")
+	assert.Nil(t, got)
+}
@@ -0,0 +1,17 @@
+package build
+
+import (
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/cobra"
+)
+
+// printBuildSummary prints the outcome of each image scheduled to build, when the builder has run any.
+func printBuildSummary(cmd *cobra.Command, images types.Images) {
+	for _, image := range images.GetAll() {
+		if image.BuildResult != nil {
+			cmd.Println(printer.DisplayBuildSummary(images))
+			return
+		}
+	}
+}
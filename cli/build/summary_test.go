@@ -0,0 +1,40 @@
+package build
+
+import (
+	"bytes"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_printBuildSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		images types.Images
+		want   string
+	}{
+		{
+			name:   "SuccessNotBuilt",
+			images: types.Images{&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, HasToBuild: true}},
+			want:   "",
+		},
+		{
+			name: "SuccessBuilt",
+			images: types.Images{
+				&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}},
+				&types.Image{ImageName: types.ImageName{Name: "bar", Tag: "0.1"}, BuildResult: &types.BuildResult{Status: types.BuildStatusSkipped}},
+			},
+			want: "build summary: 0 built, 0 failed, 0 cancelled, 1 skipped\n└── bar:0.1 skipped\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bytes.NewBufferString("")
+			cmd := &cobra.Command{}
+			cmd.SetOut(b)
+			printBuildSummary(cmd, tt.images)
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"github.com/alexandreh2ag/mib/cli/build"
 	"github.com/alexandreh2ag/mib/context"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -10,5 +12,16 @@ func TestGetBuildCmd(t *testing.T) {
 	ctx := context.TestContext(nil)
 	cmd := GetBuildCmd(ctx)
 
-	assert.Equal(t, 2, len(cmd.Commands()))
+	assert.Equal(t, 8, len(cmd.Commands()))
+}
+
+func TestGetBuildCmd_SuccessJobsFlag(t *testing.T) {
+	ctx := context.TestContext(nil)
+	viper.Reset()
+	cmd := GetBuildCmd(ctx)
+	err := cmd.PersistentFlags().Parse([]string{"--" + build.Jobs, "4"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, viper.Unmarshal(ctx.Config))
+	assert.Equal(t, 4, ctx.Config.Build.Jobs)
 }
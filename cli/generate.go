@@ -14,6 +14,7 @@ func GetGenerateCmd(ctx *context.Context) *cobra.Command {
 	cmd.AddCommand(generate.GetIndexCmd(ctx))
 	cmd.AddCommand(generate.GetAllCmd(ctx))
 	cmd.AddCommand(generate.GetDirtyCmd(ctx))
+	cmd.AddCommand(generate.GetBranchCmd(ctx))
 
 	return cmd
 }
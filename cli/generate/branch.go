@@ -0,0 +1,45 @@
+package generate
+
+import (
+	"github.com/alexandreh2ag/mib/cli/build"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/git"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/alexandreh2ag/mib/template"
+
+	"github.com/spf13/cobra"
+)
+
+func GetBranchCmd(ctx *context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "branch",
+		Short: "Generate image readme changed on current branch",
+		Long:  "Generate image readme changed between the merge-base of HEAD and the base revision, and HEAD.",
+		RunE:  GetBranchRunFn(ctx),
+	}
+
+	build.AddBaseFlag(cmd, build.DefaultBase)
+
+	return cmd
+}
+
+func GetBranchRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		base, _ := cmd.Flags().GetString(build.Base)
+		gitManager, errGit := git.CreateGit(ctx)
+		if errGit != nil {
+			return errGit
+		}
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+
+		filesChanged, errGetChanged := gitManager.GetBranchFilesChanged(base)
+		if errGetChanged != nil {
+			return errGetChanged
+		}
+		images.FlagChanged(loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
+		return template.GenerateReadmeImages(ctx, images.GetImagesToBuild())
+	}
+}
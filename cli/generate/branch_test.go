@@ -0,0 +1,89 @@
+package generate
+
+import (
+	"errors"
+	"github.com/alexandreh2ag/mib/cli/build"
+	"github.com/alexandreh2ag/mib/context"
+	mibGit "github.com/alexandreh2ag/mib/git"
+	mockgit "github.com/alexandreh2ag/mib/mock/git"
+	"github.com/alexandreh2ag/mib/template"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestGetBranchRunFn_Success(t *testing.T) {
+	template.ImageTmplPath = "tmpl/image-readme.tmpl"
+	ctx := context.TestContext(nil)
+	cmd := GetBranchCmd(ctx)
+	viper.Reset()
+	viper.SetFs(ctx.FS)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/bar/mib.yml", []byte("name: bar\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/bar/Dockerfile", []byte("FROM debian:latest"), 0644)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockgit.NewMockManager(ctrl)
+	m.EXPECT().GetBranchFilesChanged(gomock.Eq("develop")).Times(1).Return([]string{"foo/Dockerfile"}, nil)
+	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+		return m, nil
+	}
+
+	_ = cmd.Flags().Set(build.Base, "develop")
+	err := GetBranchRunFn(ctx)(cmd, []string{})
+	assert.NoError(t, err)
+	exist, _ := afero.Exists(ctx.FS, "/app/foo/README.md")
+	assert.True(t, exist)
+	exist, _ = afero.Exists(ctx.FS, "/app/bar/README.md")
+	assert.False(t, exist)
+}
+
+func TestGetBranchRunFn_FailLoadImages(t *testing.T) {
+	ctx := context.TestContext(nil)
+	cmd := GetBranchCmd(ctx)
+	viper.Reset()
+	viper.SetFs(ctx.FS)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: "), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockgit.NewMockManager(ctrl)
+	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+		return m, nil
+	}
+
+	err := GetBranchRunFn(ctx)(cmd, []string{})
+	assert.ErrorContains(t, err, "images configuration file is not valid")
+}
+
+func TestGetBranchRunFn_ErrorGetChangedFiles(t *testing.T) {
+	ctx := context.TestContext(nil)
+	cmd := GetBranchCmd(ctx)
+	viper.Reset()
+	viper.SetFs(ctx.FS)
+	_ = ctx.FS.Mkdir("/app", 0775)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockgit.NewMockManager(ctrl)
+	m.EXPECT().GetBranchFilesChanged(gomock.Eq("main")).Times(1).Return(nil, errors.New("error"))
+	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+		return m, nil
+	}
+
+	err := GetBranchRunFn(ctx)(cmd, []string{})
+	assert.ErrorContains(t, err, "error")
+}
+
+func TestGetBranchRunFn_ErrorCreateGit(t *testing.T) {
+	ctx := context.TestContext(nil)
+	cmd := GetBranchCmd(ctx)
+	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+		return nil, errors.New("error")
+	}
+	err := GetBranchRunFn(ctx)(cmd, []string{})
+	assert.ErrorContains(t, err, "error")
+}
@@ -28,7 +28,10 @@ func GetDirtyRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
 			return err
 		}
 
-		filesChanged := git.GetStageFilesChanged(gitManager)
+		filesChanged, errChanged := git.GetDirtyFilesChanged(gitManager)
+		if errChanged != nil {
+			return errChanged
+		}
 		images.FlagChanged(loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
 		return template.GenerateReadmeImages(ctx, images.GetImagesToBuild())
 	}
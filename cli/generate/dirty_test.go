@@ -30,6 +30,7 @@ func TestGetCommitRunFn_Success(t *testing.T) {
 		git.Status{},
 		nil,
 	)
+	m.EXPECT().GetSubmodulesFilesChanged().Times(1).Return([]string{}, nil)
 	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
 		return m, nil
 	}
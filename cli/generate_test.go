@@ -10,5 +10,5 @@ func TestGetGenerateCmd(t *testing.T) {
 	ctx := context.TestContext(nil)
 	cmd := GetGenerateCmd(ctx)
 
-	assert.Equal(t, 3, len(cmd.Commands()))
+	assert.Equal(t, 4, len(cmd.Commands()))
 }
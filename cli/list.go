@@ -1,26 +1,45 @@
 package cli
 
 import (
+	"github.com/alexandreh2ag/mib/cli/build"
 	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/git"
 	"github.com/alexandreh2ag/mib/loader"
 	"github.com/alexandreh2ag/mib/printer"
 	"github.com/spf13/cobra"
 )
 
 func GetListCmd(ctx *context.Context) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all images of directory",
+		Long:  "List all images of directory, with --base images changed on the current branch are highlighted.",
 		RunE:  GetListRunFn(ctx),
 	}
+
+	build.AddBaseFlag(cmd, "")
+
+	return cmd
 }
 
 func GetListRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
+		base, _ := cmd.Flags().GetString(build.Base)
 		images, err := loader.LoadImages(ctx)
 		if err != nil {
 			return err
 		}
+		if base != "" {
+			gitManager, errCreateGit := git.CreateGit(ctx)
+			if errCreateGit != nil {
+				return errCreateGit
+			}
+			filesChanged, errGetChanged := gitManager.GetBranchFilesChanged(base)
+			if errGetChanged != nil {
+				return errGetChanged
+			}
+			images.FlagChanged(loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
+		}
 		if len(images) > 0 {
 			cmd.Println(printer.DisplayImagesTree(images))
 		} else {
@@ -1,10 +1,15 @@
 package cli
 
 import (
+	"errors"
+	"github.com/alexandreh2ag/mib/cli/build"
 	"github.com/alexandreh2ag/mib/context"
+	mibGit "github.com/alexandreh2ag/mib/git"
+	mockgit "github.com/alexandreh2ag/mib/mock/git"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 	"io"
 	"testing"
 )
@@ -62,3 +67,52 @@ func TestGetListRunFn_FailLoadImages(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "images configuration file is not valid")
 }
+
+func TestGetListRunFn_SuccessWithBase(t *testing.T) {
+	ctx := context.TestContext(nil)
+	cmd := GetListCmd(ctx)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	viper.Reset()
+	viper.SetFs(ctx.FS)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockgit.NewMockManager(ctrl)
+	m.EXPECT().GetBranchFilesChanged(gomock.Eq("main")).Times(1).Return([]string{"foo/Dockerfile"}, nil)
+	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+		return m, nil
+	}
+
+	_ = cmd.Flags().Set(build.Base, "main")
+	err := GetListRunFn(ctx)(cmd, []string{})
+	assert.NoError(t, err)
+}
+
+func TestGetListRunFn_ErrorWithBase(t *testing.T) {
+	ctx := context.TestContext(nil)
+	cmd := GetListCmd(ctx)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	viper.Reset()
+	viper.SetFs(ctx.FS)
+	_ = ctx.FS.Mkdir("/app", 0775)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockgit.NewMockManager(ctrl)
+	m.EXPECT().GetBranchFilesChanged(gomock.Eq("main")).Times(1).Return(nil, errors.New("error"))
+	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+		return m, nil
+	}
+
+	_ = cmd.Flags().Set(build.Base, "main")
+	err := GetListRunFn(ctx)(cmd, []string{})
+	assert.ErrorContains(t, err, "error")
+
+	mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+		return nil, errors.New("error git")
+	}
+	err = GetListRunFn(ctx)(cmd, []string{})
+	assert.ErrorContains(t, err, "error git")
+}
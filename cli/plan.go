@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"github.com/alexandreh2ag/mib/cli/plan"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/spf13/cobra"
+)
+
+func GetPlanCmd(ctx *context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Print images to build grouped by dependency level, as JSON or CI matrix",
+	}
+	cmd.PersistentFlags().StringP(plan.Output, "o", plan.OutputJson, "Output format of the plan (json, github, gitlab)")
+	cmd.PersistentFlags().String(plan.Script, plan.DefaultScript, "Script of gitlab jobs, the image to build is in the IMAGE variable")
+
+	cmd.AddCommand(plan.GetDirtyCmd(ctx))
+	cmd.AddCommand(plan.GetCommitCmd(ctx))
+	cmd.AddCommand(plan.GetRangeCmd(ctx))
+	cmd.AddCommand(plan.GetAllCmd(ctx))
+
+	return cmd
+}
@@ -0,0 +1,27 @@
+package plan
+
+import (
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/spf13/cobra"
+)
+
+func GetAllCmd(ctx *context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "all",
+		Short: "Plan build of all images",
+		RunE:  GetAllRunFn(ctx),
+	}
+}
+
+func GetAllRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+		images.FlagAll()
+
+		return printPlan(cmd, images)
+	}
+}
@@ -0,0 +1,68 @@
+package plan
+
+import (
+	"bytes"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestGetAllRunFn(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		preFn   func(ctx *context.Context)
+		checkFn func(t *testing.T, out string, err error)
+	}{
+		{
+			name: "Success",
+			preFn: func(ctx *context.Context) {
+				_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+				_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+				_ = afero.WriteFile(ctx.FS, "/app/bar/mib.yml", []byte("name: bar\ntag: 0.1"), 0644)
+				_ = afero.WriteFile(ctx.FS, "/app/bar/Dockerfile", []byte("FROM foo:0.1"), 0644)
+			},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "levels=2\n"+
+					`matrix={"include":[{"image":"foo:0.1","path":"foo","level":0},{"image":"bar:0.1","path":"bar","level":1}]}`+"\n"+
+					`level0={"include":[{"image":"foo:0.1","path":"foo","level":0}]}`+"\n"+
+					`level1={"include":[{"image":"bar:0.1","path":"bar","level":1}]}`+"\n",
+					out,
+				)
+			},
+		},
+		{
+			name: "FailLoadImages",
+			preFn: func(ctx *context.Context) {
+				_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: "), 0644)
+				_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+			},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "configuration file is not valid")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			b := bytes.NewBufferString("")
+			cmd := GetAllCmd(ctx)
+			cmd.SetOut(b)
+			cmd.SetErr(io.Discard)
+			cmd.Flags().String(Output, OutputGithub, "")
+			viper.Reset()
+			viper.SetFs(ctx.FS)
+
+			_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+			tt.preFn(ctx)
+
+			err := cmd.Execute()
+			tt.checkFn(t, b.String(), err)
+		})
+	}
+}
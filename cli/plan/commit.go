@@ -0,0 +1,61 @@
+package plan
+
+import (
+	"fmt"
+	"github.com/alexandreh2ag/mib/cli/build"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/git"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/spf13/cobra"
+)
+
+func GetCommitCmd(ctx *context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "commit",
+		Short: "Plan build of images for specific commit",
+		RunE:  GetCommitRunFn(ctx),
+	}
+
+	cmd.Flags().String(build.Commit, "", "Commit revision (sha, short sha, tag, branch or like HEAD~1), if empty get head reference")
+	cmd.Flags().Bool(build.AllParents, false, "Compare a merge commit with all its parents instead of the first one")
+
+	return cmd
+}
+
+func GetCommitRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		commitHash, _ := cmd.Flags().GetString(build.Commit)
+		allParents, _ := cmd.Flags().GetBool(build.AllParents)
+
+		gitManager, errCreateGit := git.CreateGit(ctx)
+		if errCreateGit != nil {
+			return errCreateGit
+		}
+
+		if commitHash == "" {
+			hash, errHead := gitManager.Head()
+			if errHead != nil {
+				return fmt.Errorf("fail when get head git reference: %v", errHead)
+			}
+			commitHash = hash
+		}
+
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+		filesChanged, errGetChanged := gitManager.GetCommitFilesChanged(commitHash, allParents)
+		if errGetChanged != nil {
+			return errGetChanged
+		}
+
+		images.FlagChanged(loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
+
+		if len(images) > 0 {
+			cmd.PrintErrln(printer.DisplayImagesTree(images))
+		}
+
+		return printPlan(cmd, images)
+	}
+}
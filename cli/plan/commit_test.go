@@ -0,0 +1,143 @@
+package plan
+
+import (
+	"bytes"
+	"errors"
+	"github.com/alexandreh2ag/mib/cli/build"
+	"github.com/alexandreh2ag/mib/context"
+	mibGit "github.com/alexandreh2ag/mib/git"
+	mockgit "github.com/alexandreh2ag/mib/mock/git"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+)
+
+func TestGetCommitRunFn(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		imageData string
+		cmdArgs   []string
+		preFn     func(ctx *context.Context, ctrl *gomock.Controller)
+		checkFn   func(t *testing.T, out string, err error)
+	}{
+		{
+			name:      "Success",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(true)).Times(1).Return([]string{"foo/Dockerfile"}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+			},
+			cmdArgs: []string{"--" + build.Commit, "xxx", "--" + build.AllParents},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.NoError(t, err)
+				assert.Contains(t, out, `"name": "foo:0.1"`)
+			},
+		},
+		{
+			name:      "SuccessHead",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().Head().Times(1).Return("xxx", nil)
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return([]string{"bar/Dockerfile"}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "{\n  \"levels\": []\n}\n", out)
+			},
+		},
+		{
+			name:      "ErrorCreateGitManger",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return nil, errors.New("error")
+				}
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
+		{
+			name:      "ErrorHead",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().Head().Times(1).Return("", errors.New("error"))
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+			},
+			cmdArgs: []string{},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.EqualError(t, err, "fail when get head git reference: error")
+			},
+		},
+		{
+			name:      "FailLoadImages",
+			imageData: "name: foo\ntag: ",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+			},
+			cmdArgs: []string{"--" + build.Commit, "xxx"},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "configuration file is not valid")
+			},
+		},
+		{
+			name:      "ErrorGetCommitFilesChanged",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetCommitFilesChanged(gomock.Eq("xxx"), gomock.Eq(false)).Times(1).Return(nil, errors.New("error"))
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+			},
+			cmdArgs: []string{"--" + build.Commit, "xxx"},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			b := bytes.NewBufferString("")
+			cmd := GetCommitCmd(ctx)
+			cmd.SetOut(b)
+			cmd.SetErr(io.Discard)
+			cmd.Flags().String(Output, OutputJson, "")
+			viper.Reset()
+			viper.SetFs(ctx.FS)
+
+			_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte(tt.imageData), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+
+			tt.preFn(ctx, ctrl)
+
+			cmd.SetArgs(tt.cmdArgs)
+			err := cmd.Execute()
+			tt.checkFn(t, b.String(), err)
+		})
+	}
+}
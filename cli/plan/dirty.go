@@ -0,0 +1,40 @@
+package plan
+
+import (
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/git"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/spf13/cobra"
+)
+
+func GetDirtyCmd(ctx *context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "dirty",
+		Short: "Plan build of images with change not committed",
+		RunE:  GetDirtyRunFn(ctx),
+	}
+}
+
+func GetDirtyRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		gitManager, errGit := git.CreateGit(ctx)
+		if errGit != nil {
+			return errGit
+		}
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+		filesChanged, errChanged := git.GetDirtyFilesChanged(gitManager)
+		if errChanged != nil {
+			return errChanged
+		}
+		images.FlagChanged(loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
+		if len(images) > 0 {
+			cmd.PrintErrln(printer.DisplayImagesTree(images))
+		}
+
+		return printPlan(cmd, images)
+	}
+}
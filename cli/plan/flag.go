@@ -0,0 +1,12 @@
+package plan
+
+const (
+	Output = "output"
+	Script = "script"
+
+	OutputJson   = "json"
+	OutputGithub = "github"
+	OutputGitlab = "gitlab"
+
+	DefaultScript = `mib build image "$IMAGE"`
+)
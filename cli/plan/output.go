@@ -0,0 +1,143 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+const (
+	GitlabMatrixVariable = "IMAGE"
+	GitlabStagePrefix    = "level-"
+)
+
+type githubMatrix struct {
+	Include []githubMatrixEntry `json:"include"`
+}
+
+type githubMatrixEntry struct {
+	Image string `json:"image"`
+	Path  string `json:"path"`
+	Level int    `json:"level"`
+}
+
+type gitlabJob struct {
+	Stage    string          `yaml:"stage"`
+	Script   []string        `yaml:"script"`
+	Parallel *gitlabParallel `yaml:"parallel,omitempty"`
+}
+
+type gitlabParallel struct {
+	Matrix []map[string][]string `yaml:"matrix"`
+}
+
+// printPlan prints on stdout the images to build grouped by dependency level, as JSON or as CI matrix.
+func printPlan(cmd *cobra.Command, images types.Images) error {
+	output, _ := cmd.Flags().GetString(Output)
+	if output == "" {
+		output = OutputJson
+	}
+
+	plan := images.GetLevelsPlan()
+	switch output {
+	case OutputJson:
+		content, _ := json.MarshalIndent(plan, "", "  ")
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(content))
+	case OutputGithub:
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), formatGithubOutputs(plan))
+	case OutputGitlab:
+		script, _ := cmd.Flags().GetString(Script)
+		if script == "" {
+			script = DefaultScript
+		}
+		content, err := formatGitlabPipeline(plan, script)
+		if err != nil {
+			return fmt.Errorf("fail to format gitlab pipeline with error: %v", err)
+		}
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), content)
+	default:
+		return fmt.Errorf("output %s is not supported, use %s, %s or %s", output, OutputJson, OutputGithub, OutputGitlab)
+	}
+	return nil
+}
+
+// formatGithubOutputs returns GitHub Actions step outputs, to append to $GITHUB_OUTPUT: the number of levels, the
+// matrix of all images and the matrix of each level, so a job per level can need the job of the previous one.
+func formatGithubOutputs(plan types.LevelsPlan) string {
+	all := githubMatrix{Include: []githubMatrixEntry{}}
+	outputs := []string{fmt.Sprintf("levels=%d", len(plan.Levels))}
+	levelOutputs := []string{}
+	for _, level := range plan.Levels {
+		matrix := githubMatrix{Include: []githubMatrixEntry{}}
+		for _, image := range level.Images {
+			matrix.Include = append(matrix.Include, githubMatrixEntry{Image: image.Name, Path: image.Path, Level: level.Level})
+		}
+		all.Include = append(all.Include, matrix.Include...)
+		content, _ := json.Marshal(matrix)
+		levelOutputs = append(levelOutputs, fmt.Sprintf("level%d=%s", level.Level, content))
+	}
+	content, _ := json.Marshal(all)
+	outputs = append(outputs, fmt.Sprintf("matrix=%s", content))
+	outputs = append(outputs, levelOutputs...)
+	return strings.Join(outputs, "\n") + "\n"
+}
+
+// formatGitlabPipeline returns a GitLab CI child pipeline with a stage and a parallel matrix job per level, each
+// job of the matrix runs script with the image name in the IMAGE variable.
+func formatGitlabPipeline(plan types.LevelsPlan, script string) (string, error) {
+	stages := []string{}
+	jobNames := []string{}
+	jobs := map[string]gitlabJob{}
+	for _, level := range plan.Levels {
+		stage := fmt.Sprintf("%s%d", GitlabStagePrefix, level.Level)
+		names := []string{}
+		for _, image := range level.Images {
+			names = append(names, image.Name)
+		}
+		stages = append(stages, stage)
+		jobNames = append(jobNames, "build-"+stage)
+		jobs["build-"+stage] = gitlabJob{
+			Stage:    stage,
+			Script:   []string{script},
+			Parallel: &gitlabParallel{Matrix: []map[string][]string{{GitlabMatrixVariable: names}}},
+		}
+	}
+	// a pipeline requires at least one job
+	if len(plan.Levels) == 0 {
+		stage := GitlabStagePrefix + "0"
+		stages = append(stages, stage)
+		jobNames = append(jobNames, "nothing-to-build")
+		jobs["nothing-to-build"] = gitlabJob{Stage: stage, Script: []string{"echo nothing to build"}}
+	}
+
+	pipeline := &yaml.Node{Kind: yaml.MappingNode}
+	errStages := appendYamlField(pipeline, "stages", stages)
+	if errStages != nil {
+		return "", errStages
+	}
+	for _, name := range jobNames {
+		errJob := appendYamlField(pipeline, name, jobs[name])
+		if errJob != nil {
+			return "", errJob
+		}
+	}
+	content, err := yaml.Marshal(pipeline)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// appendYamlField appends a field to a mapping node, to keep fields in insertion order.
+func appendYamlField(node *yaml.Node, key string, value interface{}) error {
+	valueNode := &yaml.Node{}
+	err := valueNode.Encode(value)
+	if err != nil {
+		return err
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, valueNode)
+	return nil
+}
@@ -0,0 +1,150 @@
+package plan
+
+import (
+	"bytes"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func createPlanImages() types.Images {
+	tools := &types.Image{ImageName: types.ImageName{Name: "tools", Tag: "0.1"}, Path: "/app/tools", RelativeDir: "tools", HasToBuild: true}
+	base := &types.Image{ImageName: types.ImageName{Name: "base", Tag: "0.1"}, Path: "/app/base", RelativeDir: "base", HasToBuild: true}
+	child := &types.Image{ImageName: types.ImageName{Name: "child", Tag: "0.1"}, Path: "/app/child", RelativeDir: "child", HasLocalParent: true, Parent: base, Dependencies: types.Images{tools}, HasToBuild: true}
+	base.Children = types.Images{child}
+	return types.Images{base, tools}
+}
+
+func TestPrintPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		script  string
+		images  types.Images
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "SuccessJson",
+			output: OutputJson,
+			images: createPlanImages(),
+			want: `{
+  "levels": [
+    {
+      "level": 0,
+      "images": [
+        {
+          "name": "base:0.1",
+          "path": "base",
+          "parents": []
+        },
+        {
+          "name": "tools:0.1",
+          "path": "tools",
+          "parents": []
+        }
+      ]
+    },
+    {
+      "level": 1,
+      "images": [
+        {
+          "name": "child:0.1",
+          "path": "child",
+          "parents": [
+            "base:0.1",
+            "tools:0.1"
+          ]
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+		{
+			name:   "SuccessJsonEmpty",
+			images: types.Images{},
+			want:   "{\n  \"levels\": []\n}\n",
+		},
+		{
+			name:   "SuccessGithub",
+			output: OutputGithub,
+			images: createPlanImages(),
+			want: `levels=2
+matrix={"include":[{"image":"base:0.1","path":"base","level":0},{"image":"tools:0.1","path":"tools","level":0},{"image":"child:0.1","path":"child","level":1}]}
+level0={"include":[{"image":"base:0.1","path":"base","level":0},{"image":"tools:0.1","path":"tools","level":0}]}
+level1={"include":[{"image":"child:0.1","path":"child","level":1}]}
+`,
+		},
+		{
+			name:   "SuccessGithubEmpty",
+			output: OutputGithub,
+			images: types.Images{},
+			want:   "levels=0\nmatrix={\"include\":[]}\n",
+		},
+		{
+			name:   "SuccessGitlab",
+			output: OutputGitlab,
+			script: `mib build image --push "$IMAGE"`,
+			images: createPlanImages(),
+			want: `stages:
+    - level-0
+    - level-1
+build-level-0:
+    stage: level-0
+    script:
+        - mib build image --push "$IMAGE"
+    parallel:
+        matrix:
+            - IMAGE:
+                - base:0.1
+                - tools:0.1
+build-level-1:
+    stage: level-1
+    script:
+        - mib build image --push "$IMAGE"
+    parallel:
+        matrix:
+            - IMAGE:
+                - child:0.1
+`,
+		},
+		{
+			name:   "SuccessGitlabEmpty",
+			output: OutputGitlab,
+			images: types.Images{},
+			want: `stages:
+    - level-0
+nothing-to-build:
+    stage: level-0
+    script:
+        - echo nothing to build
+`,
+		},
+		{
+			name:    "ErrorOutputNotSupported",
+			output:  "yaml",
+			images:  createPlanImages(),
+			wantErr: "output yaml is not supported, use json, github or gitlab",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bytes.NewBufferString("")
+			cmd := &cobra.Command{}
+			cmd.SetOut(b)
+			cmd.Flags().String(Output, tt.output, "")
+			cmd.Flags().String(Script, tt.script, "")
+
+			err := printPlan(cmd, tt.images)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
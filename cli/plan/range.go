@@ -0,0 +1,51 @@
+package plan
+
+import (
+	"github.com/alexandreh2ag/mib/cli/build"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/git"
+	"github.com/alexandreh2ag/mib/loader"
+	"github.com/alexandreh2ag/mib/printer"
+	"github.com/spf13/cobra"
+)
+
+func GetRangeCmd(ctx *context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "range <from>..<to>",
+		Short: "Plan build of images for all commits of a range",
+		Long:  "Plan build of images for all commits reachable from <to> but not from <from>, revisions can be commit sha, tags, branches or like HEAD~3. An empty revision means HEAD.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  GetRangeRunFn(ctx),
+	}
+}
+
+func GetRangeRunFn(ctx *context.Context) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		from, to, errRange := build.ParseRange(args[0])
+		if errRange != nil {
+			return errRange
+		}
+
+		gitManager, errCreateGit := git.CreateGit(ctx)
+		if errCreateGit != nil {
+			return errCreateGit
+		}
+
+		images, err := loader.LoadImages(ctx)
+		if err != nil {
+			return err
+		}
+		filesChanged, errGetChanged := gitManager.GetRangeFilesChanged(from, to)
+		if errGetChanged != nil {
+			return errGetChanged
+		}
+
+		images.FlagChanged(loader.RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, filesChanged))
+
+		if len(images) > 0 {
+			cmd.PrintErrln(printer.DisplayImagesTree(images))
+		}
+
+		return printPlan(cmd, images)
+	}
+}
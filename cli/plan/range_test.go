@@ -0,0 +1,121 @@
+package plan
+
+import (
+	"bytes"
+	"errors"
+	"github.com/alexandreh2ag/mib/context"
+	mibGit "github.com/alexandreh2ag/mib/git"
+	mockgit "github.com/alexandreh2ag/mib/mock/git"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"io"
+	"testing"
+)
+
+func TestGetRangeRunFn(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		imageData string
+		cmdArgs   []string
+		preFn     func(ctx *context.Context, ctrl *gomock.Controller)
+		checkFn   func(t *testing.T, out string, err error)
+	}{
+		{
+			name:      "Success",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetRangeFilesChanged(gomock.Eq("v1"), gomock.Eq("HEAD")).Times(1).Return([]string{"foo/Dockerfile"}, nil)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+			},
+			cmdArgs: []string{"v1..", "--" + Output, OutputGitlab},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.NoError(t, err)
+				assert.Contains(t, out, "build-level-0:")
+				assert.Contains(t, out, "- foo:0.1")
+			},
+		},
+		{
+			name:      "ErrorParseRange",
+			imageData: "name: foo\ntag: 0.1",
+			preFn:     func(ctx *context.Context, ctrl *gomock.Controller) {},
+			cmdArgs:   []string{"v1"},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.EqualError(t, err, "range must be formatted like <from>..<to>")
+			},
+		},
+		{
+			name:      "ErrorCreateGitManger",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return nil, errors.New("error")
+				}
+			},
+			cmdArgs: []string{"v1..v2"},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
+		{
+			name:      "FailLoadImages",
+			imageData: "name: foo\ntag: ",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+			},
+			cmdArgs: []string{"v1..v2"},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "configuration file is not valid")
+			},
+		},
+		{
+			name:      "ErrorGetRangeFilesChanged",
+			imageData: "name: foo\ntag: 0.1",
+			preFn: func(ctx *context.Context, ctrl *gomock.Controller) {
+				m := mockgit.NewMockManager(ctrl)
+				m.EXPECT().GetRangeFilesChanged(gomock.Eq("v1"), gomock.Eq("v2")).Times(1).Return(nil, errors.New("error"))
+				mibGit.CreateGit = func(ctx *context.Context) (mibGit.Manager, error) {
+					return m, nil
+				}
+			},
+			cmdArgs: []string{"v1..v2"},
+			checkFn: func(t *testing.T, out string, err error) {
+				assert.EqualError(t, err, "error")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			b := bytes.NewBufferString("")
+			cmd := GetRangeCmd(ctx)
+			cmd.SetOut(b)
+			cmd.SetErr(io.Discard)
+			cmd.Flags().String(Output, OutputJson, "")
+			cmd.Flags().String(Script, DefaultScript, "")
+			viper.Reset()
+			viper.SetFs(ctx.FS)
+
+			_ = ctx.FS.Mkdir(ctx.WorkingDir, 0775)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte(tt.imageData), 0644)
+			_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+
+			tt.preFn(ctx, ctrl)
+
+			cmd.SetArgs(tt.cmdArgs)
+			err := cmd.Execute()
+			tt.checkFn(t, b.String(), err)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package cli
+
+import (
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetPlanCmd(t *testing.T) {
+	ctx := context.TestContext(nil)
+	cmd := GetPlanCmd(ctx)
+
+	assert.Equal(t, 4, len(cmd.Commands()))
+}
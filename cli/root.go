@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/alexandreh2ag/mib/container"
+	"github.com/alexandreh2ag/mib/state"
+	"github.com/alexandreh2ag/mib/state/file"
 	"github.com/alexandreh2ag/mib/template"
 	validatorMIB "github.com/alexandreh2ag/mib/validator"
 	"github.com/go-playground/validator/v10"
@@ -40,6 +42,7 @@ func GetRootCmd(ctx *context.Context) *cobra.Command {
 	viper.RegisterAlias("log_level", LogLevel)
 	cmd.AddCommand(
 		GetBuildCmd(ctx),
+		GetPlanCmd(ctx),
 		GetGenerateCmd(ctx),
 		GetListCmd(ctx),
 		GetCommitCmd(ctx),
@@ -94,6 +97,20 @@ func GetRootPreRunEFn(ctx *context.Context) func(*cobra.Command, []string) error
 			ctx.Builders[key] = builder
 		}
 
+		storeType := ctx.Config.Build.State.Type
+		if storeType == "" {
+			storeType = file.KeyStore
+		}
+		createStore, ok := state.StoreFnFactory[storeType]
+		if !ok {
+			return fmt.Errorf("build state store %s does not exist", storeType)
+		}
+		store, errCreateStore := createStore(ctx)
+		if errCreateStore != nil {
+			return fmt.Errorf("fail to create build state store %s with error: %v", storeType, errCreateStore)
+		}
+		ctx.StateStore = store
+
 		return nil
 	}
 }
@@ -122,9 +139,9 @@ func initConfig(ctx *context.Context, cmd *cobra.Command) {
 	}
 
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		ctx.Logger.Info(fmt.Sprintf("Using config file: %s", viper.ConfigFileUsed()))
 	} else {
-		fmt.Println(err)
+		ctx.Logger.Debug(err.Error())
 	}
 
 	err := viper.Unmarshal(ctx.Config)
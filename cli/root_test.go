@@ -8,7 +8,10 @@ import (
 	"github.com/alexandreh2ag/mib/container"
 	"github.com/alexandreh2ag/mib/container/docker"
 	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/state"
+	"github.com/alexandreh2ag/mib/state/file"
 	typesContainers "github.com/alexandreh2ag/mib/types/container"
+	typesState "github.com/alexandreh2ag/mib/types/state"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +31,7 @@ func Test_initConfig_SuccessConfigEmpty(t *testing.T) {
 	path := "/app"
 	_ = fsFake.Mkdir(path, 0775)
 	_ = afero.WriteFile(fsFake, fmt.Sprintf("%s/config.yml", path), []byte(""), 0644)
-	want := &config.Config{Build: config.Build{ExtensionExclude: ".md,.txt"}}
+	want := &config.Config{Build: config.Build{ExtensionExclude: ".md,.txt", Jobs: 1}}
 	initConfig(ctx, cmd)
 	assert.Equal(t, want, ctx.Config)
 }
@@ -47,6 +50,7 @@ func Test_initConfig_SuccessOverrideDefaultConfig(t *testing.T) {
 	want := &config.Config{
 		Build: config.Build{
 			ExtensionExclude: ".txt,.log",
+			Jobs:             1,
 		},
 		Template: config.Template{
 			ImagePath: "imageTmpl.tmpl",
@@ -71,6 +75,7 @@ func Test_initConfig_SuccessWithConfigFlag(t *testing.T) {
 	want := &config.Config{
 		Build: config.Build{
 			ExtensionExclude: ".txt,.log",
+			Jobs:             1,
 		},
 	}
 	viper.Set(Config, fmt.Sprintf("%s/foo.yml", path))
@@ -112,6 +117,7 @@ func TestGetRootPreRunEFn_Success(t *testing.T) {
 	err := GetRootPreRunEFn(ctx)(cmd, []string{})
 	assert.NoError(t, err)
 	assert.Equal(t, "LevelVar(INFO)", ctx.LogLevel.String())
+	assert.Equal(t, file.KeyStore, ctx.StateStore.Type())
 }
 
 func TestGetRootPreRunEFn_SuccessWithWorkingDirFlag(t *testing.T) {
@@ -221,6 +227,38 @@ func TestGetRootPreRunEFn_FailedConfigValidator(t *testing.T) {
 	assert.Contains(t, b.String(), "Key: 'Config.Build.ExtensionExclude' Error:Field validation for 'ExtensionExclude' failed on the 'required' tag")
 }
 
+func TestGetRootPreRunEFn_FailedStateStoreNotExist(t *testing.T) {
+	ctx := context.TestContext(nil)
+	cmd := GetRootCmd(ctx)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	_ = os.Setenv("HOME", ctx.WorkingDir)
+	_ = afero.WriteFile(ctx.FS, fmt.Sprintf("%s/.docker/config.json", ctx.WorkingDir), []byte("{}"), 0644)
+	_ = afero.WriteFile(ctx.FS, fmt.Sprintf("%s/config.yml", ctx.WorkingDir), []byte("build: {state: {type: wrong}}"), 0644)
+	viper.Reset()
+	viper.SetFs(ctx.FS)
+	err := GetRootPreRunEFn(ctx)(cmd, []string{})
+	assert.EqualError(t, err, "build state store wrong does not exist")
+}
+
+func TestGetRootPreRunEFn_FailedCreateStateStore(t *testing.T) {
+	ctx := context.TestContext(nil)
+	cmd := GetRootCmd(ctx)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	_ = os.Setenv("HOME", ctx.WorkingDir)
+	_ = afero.WriteFile(ctx.FS, fmt.Sprintf("%s/.docker/config.json", ctx.WorkingDir), []byte("{}"), 0644)
+	_ = afero.WriteFile(ctx.FS, fmt.Sprintf("%s/config.yml", ctx.WorkingDir), []byte("build: {state: {type: fail}}"), 0644)
+	viper.Reset()
+	viper.SetFs(ctx.FS)
+	state.StoreFnFactory["fail"] = func(ctx *context.Context) (typesState.Store, error) {
+		return nil, errors.New("error")
+	}
+	defer delete(state.StoreFnFactory, "fail")
+	err := GetRootPreRunEFn(ctx)(cmd, []string{})
+	assert.EqualError(t, err, "fail to create build state store fail with error: error")
+}
+
 func TestGetRootPreRunEFn_FailedCreateBuilders(t *testing.T) {
 	ctx := context.TestContext(nil)
 	cmd := GetRootCmd(ctx)
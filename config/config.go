@@ -12,6 +12,10 @@ func (g *Config) Get() *Config {
 type Build struct {
 	ExtensionExclude string `mapstructure:"extensionExclude" validate:"required"`
 	Docker           Docker `mapstructure:"docker"`
+	State            State  `mapstructure:"state"`
+	// Jobs is the number of images built at once, KeepGoing keeps building images not depending on a failed one.
+	Jobs      int  `mapstructure:"jobs"`
+	KeepGoing bool `mapstructure:"keepGoing"`
 }
 
 type Docker struct {
@@ -20,6 +24,13 @@ type Docker struct {
 	BuildExtraOpts  map[string]string `mapstructure:"buildExtraOpts"`
 }
 
+// State configures where the last successful build of each image is stored, a relative Path is resolved from the working dir.
+// An empty Path stores it in the user cache dir, outside the checkout.
+type State struct {
+	Type string `mapstructure:"type"`
+	Path string `mapstructure:"path"`
+}
+
 type Template struct {
 	ImagePath string `mapstructure:"imagePath" validate:"omitempty,required"`
 	IndexPath string `mapstructure:"indexPath" validate:"omitempty,required"`
@@ -33,6 +44,7 @@ func DefaultConfig() Config {
 	cfg := NewConfig()
 
 	cfg.Build.ExtensionExclude = ".md,.txt"
+	cfg.Build.Jobs = 1
 
 	return cfg
 }
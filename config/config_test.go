@@ -16,6 +16,7 @@ func TestDefaultConfig(t *testing.T) {
 	want := Config{
 		Build: Build{
 			ExtensionExclude: ".md,.txt",
+			Jobs:             1,
 		},
 	}
 	assert.Equal(t, want, got)
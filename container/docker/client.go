@@ -9,23 +9,33 @@ import (
 	"github.com/alexandreh2ag/mib/container"
 	mibContext "github.com/alexandreh2ag/mib/context"
 	"github.com/alexandreh2ag/mib/exec"
+	"github.com/alexandreh2ag/mib/loader"
 	"github.com/alexandreh2ag/mib/types"
 	typesContainers "github.com/alexandreh2ag/mib/types/container"
 	"github.com/alexandreh2ag/mib/version"
 	"github.com/distribution/reference"
 	dockerApiTypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/moby/term"
+	ociSpec "github.com/opencontainers/image-spec/specs-go/v1"
+	"regexp"
+	"slices"
 	"strings"
 )
 
 const (
-	KeyBuilder = "docker"
-	AuthUrl    = "https://index.docker.io/v1/"
-	Domain     = "docker.io"
+	KeyBuilder    = "docker"
+	AuthUrl       = "https://index.docker.io/v1/"
+	Domain        = "docker.io"
+	RedactedValue = "***"
 )
 
+// secretArgPattern matches names of build args whose value is hidden in logs and build plans, a whole segment of the
+// name separated by _ must be a secret word, so API_KEY is hidden but not KEYBOARD_LAYOUT.
+var secretArgPattern = regexp.MustCompile(`(?i)(^|_)(password|passwd|passphrase|secret|token|key|apikey|auth|credentials?|private)(_|$)`)
+
 func init() {
 	container.BuilderFnFactory[KeyBuilder] = CreateDockerBuilder
 }
@@ -56,71 +66,40 @@ func (b BuilderDocker) Type() string {
 }
 
 func (b BuilderDocker) BuildImages(images types.Images, pushImages bool) error {
-	for _, image := range images {
-		if image.HasToBuild {
-			err := b.Build(image, pushImages)
-			if err != nil {
-				return fmt.Errorf("fail to build %s with error: %v", image.GetFullName(), err)
-			}
-		}
-		if len(image.Children) > 0 {
-			errChildren := b.BuildImages(image.Children, pushImages)
-			if errChildren != nil {
-				return errChildren
-			}
+	// content hashes are cached on images, computing them before builds run in parallel avoids concurrent writes
+	for _, image := range images.GetImagesToBuild() {
+		_, err := loader.ComputeContentHash(b.ctx, image)
+		if err != nil {
+			return err
 		}
 	}
-	return nil
+	return container.ScheduleBuilds(b.ctx, images, func(image *types.Image) error {
+		return b.Build(image, pushImages)
+	})
 }
 
 func (b BuilderDocker) Build(image *types.Image, pushImages bool) error {
-	dockerCfg := b.ctx.Config.Build.Docker
 	b.ctx.Logger.Info(fmt.Sprintf("Start building %s", image.GetFullName()))
 	logger := b.ctx.Logger.With("image", image.Name)
+	// images are built in parallel, the prefix tells which build each line comes from
+	prefix := fmt.Sprintf("[%s] ", image.GetFullName())
 
-	cmdArgs := []string{"build", "--progress", "plain"}
-
-	if dockerCfg.CacheToEnable {
-		cmdArgs = append(cmdArgs, "--cache-to", "type=inline,mode=max")
-	}
-
-	if dockerCfg.CacheFromEnable {
-		cmdArgs = append(cmdArgs, "--cache-from", image.GetFullName())
-	}
-
-	if len(dockerCfg.BuildExtraOpts) > 0 {
-		for optKey, optValue := range dockerCfg.BuildExtraOpts {
-			cmdArgs = append(cmdArgs, fmt.Sprintf("--%s", optKey), optValue)
-		}
-	}
-
-	labels := []string{
-		fmt.Sprintf("%s=%s", "mib.version", version.GetFormattedVersion()),
-	}
-	argTags := sliceAddPrefixElement(image.GetNames(), "--tag")
-	cmdArgs = append(cmdArgs, argTags...)
-	argLabels := sliceAddPrefixElement(labels, "--label")
-	cmdArgs = append(cmdArgs, argLabels...)
-
-	if len(image.Platforms) > 0 {
-		cmdArgs = append(cmdArgs, []string{"--platform", strings.Join(image.Platforms, ",")}...)
+	cmdArgs, errArgs := b.getBuildArgs(image, pushImages)
+	if errArgs != nil {
+		return errArgs
 	}
 
 	stdout := bytes.NewBuffer([]byte(""))
 	stderr := bytes.NewBuffer([]byte(""))
-	if pushImages {
-		cmdArgs = append(cmdArgs, "--push")
-	}
-	cmdArgs = append(cmdArgs, ".")
 	cmd := exec.NewCmd("docker", cmdArgs...)
-	logger.Debug(fmt.Sprintf("command docker %s", cmdArgs))
+	logger.Debug(fmt.Sprintf("%scommand docker %s", prefix, redactBuildArgs(cmdArgs)))
 	cmd.SetDir(image.Path)
 	cmd.SetStdout(stdout)
 	cmd.SetStderr(stderr)
 	err := cmd.Run()
 	logsLines := strings.Split(stderr.String(), "\n")
 	for _, line := range logsLines {
-		logger.Debug(line)
+		logger.Debug(prefix + line)
 	}
 	if err != nil {
 		maxLine := 10
@@ -130,7 +109,7 @@ func (b BuilderDocker) Build(image *types.Image, pushImages bool) error {
 		}
 		errorLines := logsLines[offset:]
 		for _, line := range errorLines {
-			logger.Error(line)
+			logger.Error(prefix + line)
 		}
 		return err
 	}
@@ -140,6 +119,86 @@ func (b BuilderDocker) Build(image *types.Image, pushImages bool) error {
 	return nil
 }
 
+// GetBuildCommand returns the command line Build runs for the image, with values of secret build args redacted.
+func (b BuilderDocker) GetBuildCommand(image *types.Image, pushImages bool) ([]string, error) {
+	cmdArgs, err := b.getBuildArgs(image, pushImages)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{"docker"}, redactBuildArgs(cmdArgs)...), nil
+}
+
+// getBuildArgs returns the arguments of the docker command building the image.
+func (b BuilderDocker) getBuildArgs(image *types.Image, pushImages bool) ([]string, error) {
+	dockerCfg := b.ctx.Config.Build.Docker
+	cmdArgs := []string{"build", "--progress", "plain"}
+
+	if dockerCfg.CacheToEnable {
+		cmdArgs = append(cmdArgs, "--cache-to", "type=inline,mode=max")
+	}
+
+	if dockerCfg.CacheFromEnable {
+		cmdArgs = append(cmdArgs, "--cache-from", image.GetFullName())
+	}
+
+	optKeys := []string{}
+	for optKey := range dockerCfg.BuildExtraOpts {
+		optKeys = append(optKeys, optKey)
+	}
+	slices.Sort(optKeys)
+	for _, optKey := range optKeys {
+		cmdArgs = append(cmdArgs, fmt.Sprintf("--%s", optKey), dockerCfg.BuildExtraOpts[optKey])
+	}
+
+	buildArgs := image.GetBuildArgsValues()
+	buildArgKeys := []string{}
+	for key := range buildArgs {
+		buildArgKeys = append(buildArgKeys, key)
+	}
+	slices.Sort(buildArgKeys)
+	for _, key := range buildArgKeys {
+		cmdArgs = append(cmdArgs, "--build-arg", fmt.Sprintf("%s=%s", key, buildArgs[key]))
+	}
+
+	if image.Dockerfile != "" || image.Context != "" {
+		cmdArgs = append(cmdArgs, "--file", image.GetDockerfile())
+	}
+
+	if image.Target != "" {
+		cmdArgs = append(cmdArgs, "--target", image.Target)
+	}
+
+	contentHash, errHash := loader.ComputeContentHash(b.ctx, image)
+	if errHash != nil {
+		return nil, errHash
+	}
+	labels := []string{
+		fmt.Sprintf("%s=%s", "mib.version", version.GetFormattedVersion()),
+		fmt.Sprintf("%s=%s", types.LabelContentHash, contentHash),
+	}
+	if image.Parent != nil {
+		parentName := types.ImageName{Name: image.Parent.Name, Tag: image.Parent.Tag}
+		labels = append(labels, fmt.Sprintf("%s=%s", ociSpec.AnnotationBaseImageName, parentName.GetFullName()))
+		if image.Parent.Digest != "" {
+			labels = append(labels, fmt.Sprintf("%s=%s", ociSpec.AnnotationBaseImageDigest, image.Parent.Digest))
+		}
+	}
+	argTags := sliceAddPrefixElement(image.GetNames(), "--tag")
+	cmdArgs = append(cmdArgs, argTags...)
+	argLabels := sliceAddPrefixElement(labels, "--label")
+	cmdArgs = append(cmdArgs, argLabels...)
+
+	if len(image.Platforms) > 0 {
+		cmdArgs = append(cmdArgs, []string{"--platform", strings.Join(image.Platforms, ",")}...)
+	}
+
+	if pushImages {
+		cmdArgs = append(cmdArgs, "--push")
+	}
+	cmdArgs = append(cmdArgs, image.GetContext())
+	return cmdArgs, nil
+}
+
 func (b BuilderDocker) PushImages(images types.Images) error {
 	for _, image := range images {
 		if image.HasToBuild {
@@ -199,6 +258,93 @@ func (b BuilderDocker) Push(tag string) error {
 	return err
 }
 
+// GetDigest returns the repository digest of the image, or its local id when it has not been pushed.
+func (b BuilderDocker) GetDigest(name string) (string, error) {
+	inspect, _, err := b.client.ImageInspectWithRaw(context.Background(), name)
+	if err != nil {
+		return "", err
+	}
+	for _, repoDigest := range inspect.RepoDigests {
+		if _, digest, found := strings.Cut(repoDigest, "@"); found {
+			return digest, nil
+		}
+	}
+	return inspect.ID, nil
+}
+
+// GetPublishedLabel returns the value of the label on the image published in the registry, empty when it is not set.
+// Labels of a multi-platform image are read on its first platform since they are the same on every platform.
+func (b BuilderDocker) GetPublishedLabel(name string, label string) (string, error) {
+	stdout := bytes.NewBuffer([]byte(""))
+	stderr := bytes.NewBuffer([]byte(""))
+	cmdArgs := []string{"buildx", "imagetools", "inspect", "--format", "{{json .Image}}", name}
+	cmd := exec.NewCmd("docker", cmdArgs...)
+	b.ctx.Logger.Debug(fmt.Sprintf("command docker %s", cmdArgs))
+	cmd.SetStdout(stdout)
+	cmd.SetStderr(stderr)
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("fail to inspect %s with error: %v %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	fields := map[string]json.RawMessage{}
+	err = json.Unmarshal(stdout.Bytes(), &fields)
+	if err != nil {
+		return "", fmt.Errorf("fail to parse inspect of %s with error: %v", name, err)
+	}
+	imageConfig := stdout.Bytes()
+	_, hasConfig := fields["config"]
+	_, hasArchitecture := fields["architecture"]
+	if !hasConfig && !hasArchitecture {
+		platforms := []string{}
+		for platform := range fields {
+			platforms = append(platforms, platform)
+		}
+		if len(platforms) == 0 {
+			return "", nil
+		}
+		slices.Sort(platforms)
+		imageConfig = fields[platforms[0]]
+	}
+	image := ociSpec.Image{}
+	err = json.Unmarshal(imageConfig, &image)
+	if err != nil {
+		return "", fmt.Errorf("fail to parse inspect of %s with error: %v", name, err)
+	}
+	return image.Config.Labels[label], nil
+}
+
+// RemoveImages untags the images names from the local daemon, an image is deleted when it has no tag left.
+// Names not present locally are skipped, tags published in a registry are left untouched.
+func (b BuilderDocker) RemoveImages(names []string) error {
+	for _, name := range names {
+		b.ctx.Logger.Info(fmt.Sprintf("Remove local image %s", name))
+		_, err := b.client.ImageRemove(context.Background(), name, dockerApiTypes.ImageRemoveOptions{})
+		if errdefs.IsNotFound(err) {
+			b.ctx.Logger.Debug(fmt.Sprintf("Image %s is not present locally", name))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("fail to remove image %s with error: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// redactBuildArgs hides the values of build args whose name looks like a secret.
+func redactBuildArgs(cmdArgs []string) []string {
+	redacted := slices.Clone(cmdArgs)
+	for i := 1; i < len(redacted); i++ {
+		if redacted[i-1] != "--build-arg" {
+			continue
+		}
+		if key, _, found := strings.Cut(redacted[i], "="); found && secretArgPattern.MatchString(key) {
+			redacted[i] = key + "=" + RedactedValue
+		}
+	}
+	return redacted
+}
+
 func sliceAddPrefixElement(list []string, prefix string) []string {
 	result := []string{}
 	for _, s := range list {
@@ -5,18 +5,23 @@ import (
 	"fmt"
 	"github.com/alexandreh2ag/mib/context"
 	"github.com/alexandreh2ag/mib/exec"
+	"github.com/alexandreh2ag/mib/loader"
 	mock_docker "github.com/alexandreh2ag/mib/mock/docker"
 	mock_exec "github.com/alexandreh2ag/mib/mock/exec"
 	"github.com/alexandreh2ag/mib/types"
+	dockerApiTypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestCreateDockerBuilder_Success(t *testing.T) {
@@ -186,7 +191,7 @@ func TestBuilderDocker_PushImages_ErrorPushChild(t *testing.T) {
 func TestBuilderDocker_Build_Success(t *testing.T) {
 	ctx := context.TestContext(nil)
 	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
-	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, Path: "/app", Alias: []types.ImageName{{Name: "registry2.example.com/foo", Tag: "0.1"}}}
+	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, ContentHash: "sha256:foo", Path: "/app", Alias: []types.ImageName{{Name: "registry2.example.com/foo", Tag: "0.1"}}}
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	cmd := mock_exec.NewMockExecutable(ctrl)
@@ -195,7 +200,7 @@ func TestBuilderDocker_Build_Success(t *testing.T) {
 	cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
 	cmd.EXPECT().Run().Times(1).Return(nil)
 	defaultsArgs := []string{"build", "--progress", "plain"}
-	testArgs := []string{"--tag", "registry.example.com/foo:0.1", "--tag", "registry2.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "."}
+	testArgs := []string{"--tag", "registry.example.com/foo:0.1", "--tag", "registry2.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=sha256:foo", "."}
 	wantArgs := append(defaultsArgs, testArgs...)
 	exec.NewCmd = func(name string, arg ...string) exec.Executable {
 		assert.Equal(t, "docker", name)
@@ -213,7 +218,7 @@ func TestBuilderDocker_Build_SuccessWithBuildOpt(t *testing.T) {
 	ctx.Config.Build.Docker.CacheFromEnable = true
 	ctx.Config.Build.Docker.BuildExtraOpts = map[string]string{"provenance": "true"}
 	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
-	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, Path: "/app", Alias: []types.ImageName{{Name: "registry2.example.com/foo", Tag: "0.1"}}}
+	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, ContentHash: "sha256:foo", Path: "/app", Alias: []types.ImageName{{Name: "registry2.example.com/foo", Tag: "0.1"}}}
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	cmd := mock_exec.NewMockExecutable(ctrl)
@@ -222,7 +227,112 @@ func TestBuilderDocker_Build_SuccessWithBuildOpt(t *testing.T) {
 	cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
 	cmd.EXPECT().Run().Times(1).Return(nil)
 	defaultsArgs := []string{"build", "--progress", "plain", "--cache-to", "type=inline,mode=max", "--cache-from", "registry.example.com/foo:0.1", "--provenance", "true"}
-	testArgs := []string{"--tag", "registry.example.com/foo:0.1", "--tag", "registry2.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "."}
+	testArgs := []string{"--tag", "registry.example.com/foo:0.1", "--tag", "registry2.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=sha256:foo", "."}
+	wantArgs := append(defaultsArgs, testArgs...)
+	exec.NewCmd = func(name string, arg ...string) exec.Executable {
+		assert.Equal(t, "docker", name)
+		assert.Equal(t, wantArgs, arg)
+		return cmd
+	}
+	b := BuilderDocker{ctx: ctx, AuthConfig: &auth}
+	err := b.Build(image, false)
+	assert.NoError(t, err)
+}
+
+func TestBuilderDocker_Build_SuccessWithBuildArgs(t *testing.T) {
+	t.Setenv("MIB_TEST_COMMIT", "abcdef")
+	ctx := context.TestContext(nil)
+	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
+	parent := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/base", Tag: "0.1"}, BuildArgs: map[string]string{"BASE_TAG": "3.19", "VERSION": "1.0"}}
+	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, ContentHash: "sha256:foo", Path: "/app", Parent: parent, HasLocalParent: true, BuildArgs: map[string]string{"VERSION": "2.0", "COMMIT": "${MIB_TEST_COMMIT}"}}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := mock_exec.NewMockExecutable(ctrl)
+	cmd.EXPECT().SetDir(gomock.Eq("/app")).Times(1)
+	cmd.EXPECT().SetStdout(gomock.Any()).Times(1)
+	cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
+	cmd.EXPECT().Run().Times(1).Return(nil)
+	defaultsArgs := []string{"build", "--progress", "plain"}
+	testArgs := []string{"--build-arg", "BASE_TAG=3.19", "--build-arg", "COMMIT=abcdef", "--build-arg", "VERSION=2.0", "--tag", "registry.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=sha256:foo", "--label", "org.opencontainers.image.base.name=registry.example.com/base:0.1", "."}
+	wantArgs := append(defaultsArgs, testArgs...)
+	exec.NewCmd = func(name string, arg ...string) exec.Executable {
+		assert.Equal(t, "docker", name)
+		assert.Equal(t, wantArgs, arg)
+		return cmd
+	}
+	b := BuilderDocker{ctx: ctx, AuthConfig: &auth}
+	err := b.Build(image, false)
+	assert.NoError(t, err)
+}
+
+func TestBuilderDocker_Build_SuccessWithDockerfileContextAndTarget(t *testing.T) {
+	ctx := context.TestContext(nil)
+	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
+	image := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, ContentHash: "sha256:foo", Path: "/app/foo", Dockerfile: "docker/prod.Dockerfile", Context: "..", Target: "runtime"}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := mock_exec.NewMockExecutable(ctrl)
+	cmd.EXPECT().SetDir(gomock.Eq("/app/foo")).Times(1)
+	cmd.EXPECT().SetStdout(gomock.Any()).Times(1)
+	cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
+	cmd.EXPECT().Run().Times(1).Return(nil)
+	defaultsArgs := []string{"build", "--progress", "plain"}
+	testArgs := []string{"--file", "docker/prod.Dockerfile", "--target", "runtime", "--tag", "foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=sha256:foo", ".."}
+	wantArgs := append(defaultsArgs, testArgs...)
+	exec.NewCmd = func(name string, arg ...string) exec.Executable {
+		assert.Equal(t, "docker", name)
+		assert.Equal(t, wantArgs, arg)
+		return cmd
+	}
+	b := BuilderDocker{ctx: ctx, AuthConfig: &auth}
+	err := b.Build(image, false)
+	assert.NoError(t, err)
+}
+
+func TestBuilderDocker_Build_SuccessWithContext(t *testing.T) {
+	ctx := context.TestContext(nil)
+	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
+	image := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, ContentHash: "sha256:foo", Path: "/app/foo", Context: "../shared"}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := mock_exec.NewMockExecutable(ctrl)
+	cmd.EXPECT().SetDir(gomock.Eq("/app/foo")).Times(1)
+	cmd.EXPECT().SetStdout(gomock.Any()).Times(1)
+	cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
+	cmd.EXPECT().Run().Times(1).Return(nil)
+	defaultsArgs := []string{"build", "--progress", "plain"}
+	testArgs := []string{"--file", "Dockerfile", "--tag", "foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=sha256:foo", "../shared"}
+	wantArgs := append(defaultsArgs, testArgs...)
+	exec.NewCmd = func(name string, arg ...string) exec.Executable {
+		assert.Equal(t, "docker", name)
+		assert.Equal(t, wantArgs, arg)
+		return cmd
+	}
+	b := BuilderDocker{ctx: ctx, AuthConfig: &auth}
+	err := b.Build(image, false)
+	assert.NoError(t, err)
+}
+
+func TestBuilderDocker_Build_SuccessWithParentDigest(t *testing.T) {
+	ctx := context.TestContext(nil)
+	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
+	parent := &types.Image{ImageName: types.ImageName{Name: "alpine", Tag: "3.19", Digest: "sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b"}}
+	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, ContentHash: "sha256:foo", Path: "/app", Parent: parent}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := mock_exec.NewMockExecutable(ctrl)
+	cmd.EXPECT().SetDir(gomock.Eq("/app")).Times(1)
+	cmd.EXPECT().SetStdout(gomock.Any()).Times(1)
+	cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
+	cmd.EXPECT().Run().Times(1).Return(nil)
+	defaultsArgs := []string{"build", "--progress", "plain"}
+	testArgs := []string{
+		"--tag", "registry.example.com/foo:0.1",
+		"--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=sha256:foo",
+		"--label", "org.opencontainers.image.base.name=alpine:3.19",
+		"--label", "org.opencontainers.image.base.digest=sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b",
+		".",
+	}
 	wantArgs := append(defaultsArgs, testArgs...)
 	exec.NewCmd = func(name string, arg ...string) exec.Executable {
 		assert.Equal(t, "docker", name)
@@ -237,7 +347,7 @@ func TestBuilderDocker_Build_SuccessWithBuildOpt(t *testing.T) {
 func TestBuilderDocker_Build_SuccessWithPush(t *testing.T) {
 	ctx := context.TestContext(nil)
 	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
-	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, Path: "/app", Alias: []types.ImageName{{Name: "registry2.example.com/foo", Tag: "0.1"}}}
+	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, ContentHash: "sha256:foo", Path: "/app", Alias: []types.ImageName{{Name: "registry2.example.com/foo", Tag: "0.1"}}}
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	cmd := mock_exec.NewMockExecutable(ctrl)
@@ -246,7 +356,7 @@ func TestBuilderDocker_Build_SuccessWithPush(t *testing.T) {
 	cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
 	cmd.EXPECT().Run().Times(1).Return(nil)
 	defaultsArgs := []string{"build", "--progress", "plain"}
-	testArgs := []string{"--tag", "registry.example.com/foo:0.1", "--tag", "registry2.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--push", "."}
+	testArgs := []string{"--tag", "registry.example.com/foo:0.1", "--tag", "registry2.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=sha256:foo", "--push", "."}
 	wantArgs := append(defaultsArgs, testArgs...)
 	exec.NewCmd = func(name string, arg ...string) exec.Executable {
 		assert.Equal(t, "docker", name)
@@ -261,7 +371,7 @@ func TestBuilderDocker_Build_SuccessWithPush(t *testing.T) {
 func TestBuilderDocker_Build_SuccessMultiPlatforms(t *testing.T) {
 	ctx := context.TestContext(nil)
 	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
-	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, Path: "/app", Platforms: []string{"linux/amd64", "linux/arm64/v8"}}
+	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, ContentHash: "sha256:foo", Path: "/app", Platforms: []string{"linux/amd64", "linux/arm64/v8"}}
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	cmd := mock_exec.NewMockExecutable(ctrl)
@@ -270,7 +380,7 @@ func TestBuilderDocker_Build_SuccessMultiPlatforms(t *testing.T) {
 	cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
 	cmd.EXPECT().Run().Times(1).Return(nil)
 	defaultsArgs := []string{"build", "--progress", "plain"}
-	testArgs := []string{"--tag", "registry.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--platform", "linux/amd64,linux/arm64/v8", "."}
+	testArgs := []string{"--tag", "registry.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=sha256:foo", "--platform", "linux/amd64,linux/arm64/v8", "."}
 	wantArgs := append(defaultsArgs, testArgs...)
 	exec.NewCmd = func(name string, arg ...string) exec.Executable {
 		assert.Equal(t, "docker", name)
@@ -282,10 +392,33 @@ func TestBuilderDocker_Build_SuccessMultiPlatforms(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestBuilderDocker_Build_SuccessComputeContentHash(t *testing.T) {
+	ctx := context.TestContext(nil)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM alpine:3.19"), 0644)
+	image := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", RelativeDir: "foo"}
+	want, _ := loader.ComputeContentHash(ctx, &types.Image{ImageName: image.ImageName, Path: image.Path, RelativeDir: image.RelativeDir})
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := mock_exec.NewMockExecutable(ctrl)
+	cmd.EXPECT().SetDir(gomock.Eq("/app/foo")).Times(1)
+	cmd.EXPECT().SetStdout(gomock.Any()).Times(1)
+	cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
+	cmd.EXPECT().Run().Times(1).Return(nil)
+	wantArgs := []string{"build", "--progress", "plain", "--tag", "foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=" + want, "."}
+	exec.NewCmd = func(name string, arg ...string) exec.Executable {
+		assert.Equal(t, wantArgs, arg)
+		return cmd
+	}
+	b := BuilderDocker{ctx: ctx}
+	err := b.Build(image, false)
+	assert.NoError(t, err)
+	assert.Equal(t, want, image.ContentHash)
+}
+
 func TestBuilderDocker_Build_Error(t *testing.T) {
 	ctx := context.TestContext(nil)
 	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
-	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, Path: "/app"}
+	image := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, ContentHash: "sha256:foo", Path: "/app"}
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	cmd := mock_exec.NewMockExecutable(ctrl)
@@ -294,7 +427,7 @@ func TestBuilderDocker_Build_Error(t *testing.T) {
 	cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
 	cmd.EXPECT().Run().Times(1).Return(errors.New("fail build"))
 	defaultsArgs := []string{"build", "--progress", "plain"}
-	testArgs := []string{"--tag", "registry.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "."}
+	testArgs := []string{"--tag", "registry.example.com/foo:0.1", "--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=sha256:foo", "."}
 	wantArgs := append(defaultsArgs, testArgs...)
 	exec.NewCmd = func(name string, arg ...string) exec.Executable {
 		assert.Equal(t, "docker", name)
@@ -307,6 +440,65 @@ func TestBuilderDocker_Build_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "fail build")
 }
 
+func TestBuilderDocker_GetBuildCommand(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.Config.Build.Docker.BuildExtraOpts = map[string]string{"provenance": "true", "network": "host"}
+	image := &types.Image{
+		ImageName:   types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"},
+		Path:        "/app",
+		ContentHash: "sha256:foo",
+		BuildArgs:   map[string]string{"NPM_TOKEN": "secret", "VERSION": "2.0", "DB_PASSWORD": "secret"},
+	}
+	exec.NewCmd = func(name string, arg ...string) exec.Executable {
+		t.Fatal("docker must not be invoked")
+		return nil
+	}
+	b := BuilderDocker{ctx: ctx}
+	got, err := b.GetBuildCommand(image, true)
+	assert.NoError(t, err)
+	want := []string{
+		"docker", "build", "--progress", "plain",
+		"--network", "host", "--provenance", "true",
+		"--build-arg", "DB_PASSWORD=***", "--build-arg", "NPM_TOKEN=***", "--build-arg", "VERSION=2.0",
+		"--tag", "registry.example.com/foo:0.1",
+		"--label", "mib.version=develop-SNAPSHOT", "--label", "mib.content-hash=sha256:foo",
+		"--push", ".",
+	}
+	assert.Equal(t, want, got)
+}
+
+func Test_redactBuildArgs(t *testing.T) {
+	args := []string{"--build-arg", "API_KEY=abc", "--build-arg", "COMMIT=abcdef", "--label", "token=abc", "--build-arg", "AUTH"}
+	want := []string{"--build-arg", "API_KEY=***", "--build-arg", "COMMIT=abcdef", "--label", "token=abc", "--build-arg", "AUTH"}
+	assert.Equal(t, want, redactBuildArgs(args))
+	assert.Equal(t, "API_KEY=abc", args[1])
+}
+
+func Test_redactBuildArgs_NameSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		redacted bool
+	}{
+		{name: "API_KEY", redacted: true},
+		{name: "github_token", redacted: true},
+		{name: "NPM_AUTH_TOKEN", redacted: true},
+		{name: "SECRET", redacted: true},
+		{name: "DB_PASSWORD_FILE", redacted: true},
+		{name: "REGISTRY_CREDENTIALS", redacted: true},
+		{name: "AUTHOR", redacted: false},
+		{name: "KEYBOARD_LAYOUT", redacted: false},
+		{name: "MONKEY", redacted: false},
+		{name: "TOKENIZER_MODEL", redacted: false},
+		{name: "PRIVATEER", redacted: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactBuildArgs([]string{"--build-arg", tt.name + "=value"})
+			assert.Equal(t, tt.redacted, got[1] == tt.name+"="+RedactedValue)
+		})
+	}
+}
+
 func TestBuilderDocker_BuildImages_Success(t *testing.T) {
 
 	ctx := context.TestContext(nil)
@@ -328,6 +520,8 @@ func TestBuilderDocker_BuildImages_Success(t *testing.T) {
 	b := BuilderDocker{ctx: ctx, AuthConfig: &auth}
 	err := b.BuildImages(images, false)
 	assert.NoError(t, err)
+	assert.True(t, image1.IsBuilt)
+	assert.True(t, image1Child.IsBuilt)
 }
 
 func TestBuilderDocker_BuildImages_ErrorBuild(t *testing.T) {
@@ -377,6 +571,240 @@ func TestBuilderDocker_BuildImages_ErrorBuildChild(t *testing.T) {
 	err := b.BuildImages(images, false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "error")
+	assert.True(t, image1.IsBuilt)
+	assert.False(t, image1Child.IsBuilt)
+}
+
+// barrierFs holds the opening of each path until all of them are opened, or a timeout when they are opened one
+// after the other, so that builds running in parallel read them at the same time.
+type barrierFs struct {
+	afero.Fs
+	arrived map[string]*sync.Once
+	wg      *sync.WaitGroup
+}
+
+func newBarrierFs(fs afero.Fs, paths ...string) barrierFs {
+	barrier := barrierFs{Fs: fs, arrived: map[string]*sync.Once{}, wg: &sync.WaitGroup{}}
+	for _, path := range paths {
+		barrier.arrived[path] = &sync.Once{}
+	}
+	barrier.wg.Add(len(paths))
+	return barrier
+}
+
+func (fs barrierFs) Open(name string) (afero.File, error) {
+	if once, ok := fs.arrived[name]; ok {
+		once.Do(fs.wg.Done)
+		done := make(chan struct{})
+		go func() {
+			fs.wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return fs.Fs.Open(name)
+}
+
+func TestBuilderDocker_BuildImages_SuccessParallelSharedParent(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.Config.Build.Jobs = 2
+	ctx.FS = newBarrierFs(ctx.FS, "/app/foo-bar/Dockerfile", "/app/foo-baz/Dockerfile")
+	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
+	parent := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, Path: "/app/foo"}
+	child1 := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo-bar", Tag: "0.1"}, Path: "/app/foo-bar", HasLocalParent: true, Parent: parent, HasToBuild: true}
+	child2 := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo-baz", Tag: "0.1"}, Path: "/app/foo-baz", HasLocalParent: true, Parent: parent, HasToBuild: true}
+	parent.Children = types.Images{child1, child2}
+	images := types.Images{parent}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := mock_exec.NewMockExecutable(ctrl)
+	cmd.EXPECT().SetDir(gomock.Any()).Times(2)
+	cmd.EXPECT().SetStdout(gomock.Any()).Times(2)
+	cmd.EXPECT().SetStderr(gomock.Any()).Times(2)
+	cmd.EXPECT().Run().Times(2).Return(nil)
+
+	exec.NewCmd = func(name string, arg ...string) exec.Executable {
+		return cmd
+	}
+	b := BuilderDocker{ctx: ctx, AuthConfig: &auth}
+	err := b.BuildImages(images, false)
+	assert.NoError(t, err)
+	assert.True(t, child1.IsBuilt)
+	assert.True(t, child2.IsBuilt)
+	assert.NotEmpty(t, parent.ContentHash)
+}
+
+func TestBuilderDocker_BuildImages_SuccessDependencyOrder(t *testing.T) {
+	ctx := context.TestContext(nil)
+	auth := AuthConfig{AuthConfigs: map[string]registry.AuthConfig{}}
+	tools := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/tools", Tag: "0.1"}, Path: "/app/tools", HasToBuild: true}
+	image1 := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"}, Path: "/app/foo", Dependencies: types.Images{tools}, HasToBuild: true}
+	tools.Dependents = types.Images{image1}
+	images := types.Images{image1, tools}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := mock_exec.NewMockExecutable(ctrl)
+	gomock.InOrder(
+		cmd.EXPECT().SetDir(gomock.Eq("/app/tools")).Times(1),
+		cmd.EXPECT().SetDir(gomock.Eq("/app/foo")).Times(1),
+	)
+	cmd.EXPECT().SetStdout(gomock.Any()).Times(2)
+	cmd.EXPECT().SetStderr(gomock.Any()).Times(2)
+	cmd.EXPECT().Run().Times(2).Return(nil)
+
+	exec.NewCmd = func(name string, arg ...string) exec.Executable {
+		return cmd
+	}
+	b := BuilderDocker{ctx: ctx, AuthConfig: &auth}
+	err := b.BuildImages(images, false)
+	assert.NoError(t, err)
+}
+
+func TestBuilderDocker_GetDigest(t *testing.T) {
+	tests := []struct {
+		name    string
+		inspect dockerApiTypes.ImageInspect
+		err     error
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "SuccessRepoDigest",
+			inspect: dockerApiTypes.ImageInspect{ID: "sha256:local", RepoDigests: []string{"registry.example.com/foo@sha256:remote"}},
+			want:    "sha256:remote",
+		},
+		{
+			name:    "SuccessLocalId",
+			inspect: dockerApiTypes.ImageInspect{ID: "sha256:local"},
+			want:    "sha256:local",
+		},
+		{
+			name:    "ErrorInspect",
+			err:     errors.New("error"),
+			wantErr: "error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			clientDocker := mock_docker.NewMockAPIClient(ctrl)
+			clientDocker.EXPECT().ImageInspectWithRaw(gomock.Any(), gomock.Eq("registry.example.com/foo:0.1")).Times(1).Return(tt.inspect, nil, tt.err)
+			b := BuilderDocker{ctx: context.TestContext(nil), client: clientDocker}
+			got, err := b.GetDigest("registry.example.com/foo:0.1")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuilderDocker_GetPublishedLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdout  string
+		err     error
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "SuccessSinglePlatform",
+			stdout: `{"architecture":"amd64","os":"linux","config":{"Labels":{"mib.content-hash":"sha256:foo"}}}`,
+			want:   "sha256:foo",
+		},
+		{
+			name:   "SuccessMultiPlatforms",
+			stdout: `{"linux/arm64":{"architecture":"arm64","config":{"Labels":{"mib.content-hash":"sha256:foo"}}},"linux/amd64":{"architecture":"amd64","config":{"Labels":{"mib.content-hash":"sha256:foo"}}}}`,
+			want:   "sha256:foo",
+		},
+		{
+			name:   "SuccessWithoutLabel",
+			stdout: `{"architecture":"amd64","os":"linux","config":{}}`,
+			want:   "",
+		},
+		{
+			name:    "ErrorInspect",
+			err:     errors.New("exit status 1"),
+			wantErr: "fail to inspect registry.example.com/foo:0.1 with error: exit status 1 ",
+		},
+		{
+			name:    "ErrorParse",
+			stdout:  `{wrong`,
+			wantErr: "fail to parse inspect of registry.example.com/foo:0.1 with error: invalid character 'w' looking for beginning of object key string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			var stdout io.Writer
+			cmd := mock_exec.NewMockExecutable(ctrl)
+			cmd.EXPECT().SetStdout(gomock.Any()).Times(1).Do(func(w io.Writer) { stdout = w })
+			cmd.EXPECT().SetStderr(gomock.Any()).Times(1)
+			cmd.EXPECT().Run().Times(1).DoAndReturn(func() error {
+				_, _ = stdout.Write([]byte(tt.stdout))
+				return tt.err
+			})
+			exec.NewCmd = func(name string, arg ...string) exec.Executable {
+				assert.Equal(t, "docker", name)
+				assert.Equal(t, []string{"buildx", "imagetools", "inspect", "--format", "{{json .Image}}", "registry.example.com/foo:0.1"}, arg)
+				return cmd
+			}
+			b := BuilderDocker{ctx: context.TestContext(nil)}
+			got, err := b.GetPublishedLabel("registry.example.com/foo:0.1", types.LabelContentHash)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuilderDocker_RemoveImages_Success(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	clientDocker := mock_docker.NewMockAPIClient(ctrl)
+	gomock.InOrder(
+		clientDocker.EXPECT().ImageRemove(gomock.Any(), gomock.Eq("foo:0.1"), gomock.Any()).Times(1).Return(nil, nil),
+		clientDocker.EXPECT().ImageRemove(gomock.Any(), gomock.Eq("foo:latest"), gomock.Any()).Times(1).Return(nil, nil),
+	)
+	b := BuilderDocker{ctx: ctx, client: clientDocker}
+	err := b.RemoveImages([]string{"foo:0.1", "foo:latest"})
+	assert.NoError(t, err)
+}
+
+func TestBuilderDocker_RemoveImages_SuccessNotFound(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	clientDocker := mock_docker.NewMockAPIClient(ctrl)
+	gomock.InOrder(
+		clientDocker.EXPECT().ImageRemove(gomock.Any(), gomock.Eq("foo:0.1"), gomock.Any()).Times(1).Return(nil, errdefs.NotFound(errors.New("No such image: foo:0.1"))),
+		clientDocker.EXPECT().ImageRemove(gomock.Any(), gomock.Eq("foo:latest"), gomock.Any()).Times(1).Return(nil, nil),
+	)
+	b := BuilderDocker{ctx: ctx, client: clientDocker}
+	err := b.RemoveImages([]string{"foo:0.1", "foo:latest"})
+	assert.NoError(t, err)
+}
+
+func TestBuilderDocker_RemoveImages_Error(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	clientDocker := mock_docker.NewMockAPIClient(ctrl)
+	clientDocker.EXPECT().ImageRemove(gomock.Any(), gomock.Eq("foo:0.1"), gomock.Any()).Times(1).Return(nil, errors.New("error"))
+	b := BuilderDocker{ctx: ctx, client: clientDocker}
+	err := b.RemoveImages([]string{"foo:0.1", "foo:latest"})
+	assert.EqualError(t, err, "fail to remove image foo:0.1 with error: error")
 }
 
 func Test_sliceAddPrefixElement(t *testing.T) {
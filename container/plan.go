@@ -0,0 +1,40 @@
+package container
+
+import (
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/alexandreh2ag/mib/types/container"
+)
+
+// GetBuildPlan returns the builds the builder would run for images to build, without running them.
+func GetBuildPlan(builder container.BuilderImage, images types.Images, pushImages bool) (types.BuildPlan, error) {
+	plan := types.BuildPlan{Builds: []types.BuildStep{}, Remove: []string{}}
+	for _, image := range images.GetBuildOrder() {
+		if !image.HasToBuild {
+			continue
+		}
+		command, err := builder.GetBuildCommand(image, pushImages)
+		if err != nil {
+			return types.BuildPlan{}, err
+		}
+		step := types.BuildStep{
+			Name:      image.GetFullName(),
+			Path:      image.RelativeDir,
+			Dir:       image.Path,
+			Tags:      image.GetNames(),
+			Platforms: image.Platforms,
+			Command:   command,
+			Push:      []string{},
+		}
+		if step.Platforms == nil {
+			step.Platforms = []string{}
+		}
+		if image.Parent != nil {
+			step.Parent = image.Parent.GetFullName()
+		}
+		if pushImages {
+			step.Push = image.GetNames()
+		}
+		plan.Builds = append(plan.Builds, step)
+	}
+	return plan, nil
+}
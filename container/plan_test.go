@@ -0,0 +1,53 @@
+package container
+
+import (
+	"errors"
+	mock_types_container "github.com/alexandreh2ag/mib/mock/types/container"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestGetBuildPlan_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	base := &types.Image{ImageName: types.ImageName{Name: "base", Tag: "0.1"}, Path: "/app/base", RelativeDir: "base", Parent: &types.Image{ImageName: types.ImageName{Name: "alpine", Tag: "3.19"}}, Platforms: []string{"linux/amd64"}, HasToBuild: true}
+	child := &types.Image{ImageName: types.ImageName{Name: "child", Tag: "0.1"}, Alias: []types.ImageName{{Name: "child", Tag: "latest"}}, Path: "/app/child", RelativeDir: "child", HasLocalParent: true, Parent: base, HasToBuild: true}
+	base.Children = types.Images{child}
+	other := &types.Image{ImageName: types.ImageName{Name: "other", Tag: "0.1"}, Path: "/app/other", RelativeDir: "other"}
+	builder := mock_types_container.NewMockBuilderImage(ctrl)
+	builder.EXPECT().GetBuildCommand(gomock.Eq(base), gomock.Eq(true)).Times(1).Return([]string{"docker", "build", "base"}, nil)
+	builder.EXPECT().GetBuildCommand(gomock.Eq(child), gomock.Eq(true)).Times(1).Return([]string{"docker", "build", "child"}, nil)
+
+	got, err := GetBuildPlan(builder, types.Images{other, base}, true)
+	assert.NoError(t, err)
+	want := types.BuildPlan{Builds: []types.BuildStep{
+		{Name: "base:0.1", Path: "base", Dir: "/app/base", Tags: []string{"base:0.1"}, Platforms: []string{"linux/amd64"}, Parent: "alpine:3.19", Command: []string{"docker", "build", "base"}, Push: []string{"base:0.1"}},
+		{Name: "child:0.1", Path: "child", Dir: "/app/child", Tags: []string{"child:0.1", "child:latest"}, Platforms: []string{}, Parent: "base:0.1", Command: []string{"docker", "build", "child"}, Push: []string{"child:0.1", "child:latest"}},
+	}, Remove: []string{}}
+	assert.Equal(t, want, got)
+}
+
+func TestGetBuildPlan_SuccessWithoutPush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	image := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", RelativeDir: "foo", HasToBuild: true}
+	builder := mock_types_container.NewMockBuilderImage(ctrl)
+	builder.EXPECT().GetBuildCommand(gomock.Eq(image), gomock.Eq(false)).Times(1).Return([]string{"docker", "build", "."}, nil)
+
+	got, err := GetBuildPlan(builder, types.Images{image}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, got.Builds[0].Push)
+}
+
+func TestGetBuildPlan_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	image := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", HasToBuild: true}
+	builder := mock_types_container.NewMockBuilderImage(ctrl)
+	builder.EXPECT().GetBuildCommand(gomock.Any(), gomock.Any()).Times(1).Return(nil, errors.New("error"))
+
+	_, err := GetBuildPlan(builder, types.Images{image}, false)
+	assert.EqualError(t, err, "error")
+}
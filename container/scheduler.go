@@ -0,0 +1,122 @@
+package container
+
+import (
+	"fmt"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/types"
+	"strings"
+	"time"
+)
+
+type buildResult struct {
+	image    *types.Image
+	err      error
+	duration time.Duration
+}
+
+// ScheduleBuilds calls build for each image to build, with up to ctx.Config.Build.Jobs builds at once. An image
+// starts as soon as its local parent and local dependencies to build are built. When a build fails, its
+// descendants are cancelled and, unless ctx.Config.Build.KeepGoing is set, no other build is started. Running
+// builds are always awaited, and the outcome of each image is kept in its BuildResult.
+func ScheduleBuilds(ctx *context.Context, images types.Images, build func(image *types.Image) error) error {
+	jobs := ctx.Config.Build.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	toBuild := types.Images{}
+	scheduled := map[*types.Image]bool{}
+	for _, image := range images.GetBuildOrder() {
+		if image.HasToBuild {
+			toBuild = append(toBuild, image)
+			scheduled[image] = true
+		}
+	}
+	waitFor := map[*types.Image]types.Images{}
+	for _, image := range toBuild {
+		requirements := image.GetLocalDependencies()
+		if image.HasLocalParent {
+			requirements = append(requirements, image.Parent)
+		}
+		for _, requirement := range requirements {
+			if scheduled[requirement] {
+				waitFor[image] = append(waitFor[image], requirement)
+			}
+		}
+	}
+
+	started := map[*types.Image]bool{}
+	results := make(chan buildResult)
+	running := 0
+	failed := types.Images{}
+	for {
+		for _, image := range toBuild {
+			if (len(failed) > 0 && !ctx.Config.Build.KeepGoing) || running >= jobs {
+				break
+			}
+			if started[image] || image.BuildResult != nil || !isReady(waitFor[image]) {
+				continue
+			}
+			started[image] = true
+			running++
+			go func(image *types.Image) {
+				start := time.Now()
+				err := build(image)
+				results <- buildResult{image: image, err: err, duration: time.Since(start)}
+			}(image)
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		if result.err == nil {
+			result.image.IsBuilt = true
+			result.image.BuildResult = &types.BuildResult{Status: types.BuildStatusBuilt, Duration: result.duration}
+			continue
+		}
+		result.image.BuildResult = &types.BuildResult{Status: types.BuildStatusFailed, Duration: result.duration, Err: result.err}
+		failed = append(failed, result.image)
+		for _, image := range toBuild {
+			if image.BuildResult == nil && !started[image] && requiresAny(waitFor[image], result.image) {
+				image.BuildResult = &types.BuildResult{Status: types.BuildStatusCancelled, FailedImage: result.image}
+				ctx.Logger.Warn(fmt.Sprintf("Cancel building %s, %s failed", image.GetFullName(), result.image.GetFullName()))
+			}
+		}
+	}
+
+	for _, image := range toBuild {
+		if image.BuildResult == nil {
+			image.BuildResult = &types.BuildResult{Status: types.BuildStatusSkipped}
+		}
+	}
+	switch len(failed) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("fail to build %s with error: %v", failed[0].GetFullName(), failed[0].BuildResult.Err)
+	default:
+		return fmt.Errorf("fail to build %d images: %s", len(failed), strings.Join(failed.GetAllNames(false), ", "))
+	}
+}
+
+func isReady(requirements types.Images) bool {
+	for _, requirement := range requirements {
+		if requirement.BuildResult == nil || requirement.BuildResult.Status != types.BuildStatusBuilt {
+			return false
+		}
+	}
+	return true
+}
+
+// requiresAny returns true when one of requirements is the failed image or has been cancelled,
+// requirements come before in build order so cancellation spreads to all descendants in one pass.
+func requiresAny(requirements types.Images, failed *types.Image) bool {
+	for _, requirement := range requirements {
+		if requirement == failed || (requirement.BuildResult != nil && requirement.BuildResult.Status == types.BuildStatusCancelled) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package container
+
+import (
+	"bytes"
+	"errors"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduleBuilds_SuccessOrder(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.Config.Build.Jobs = 4
+	tools := &types.Image{ImageName: types.ImageName{Name: "tools", Tag: "0.1"}, Path: "/app/tools", HasToBuild: true}
+	base := &types.Image{ImageName: types.ImageName{Name: "base", Tag: "0.1"}, Path: "/app/base", HasToBuild: true}
+	child := &types.Image{ImageName: types.ImageName{Name: "child", Tag: "0.1"}, Path: "/app/child", HasLocalParent: true, Parent: base, Dependencies: types.Images{tools}, HasToBuild: true}
+	base.Children = types.Images{child}
+	skipped := &types.Image{ImageName: types.ImageName{Name: "skipped", Tag: "0.1"}, Path: "/app/skipped"}
+	skippedChild := &types.Image{ImageName: types.ImageName{Name: "skipped-child", Tag: "0.1"}, Path: "/app/skipped-child", HasLocalParent: true, Parent: skipped, HasToBuild: true}
+	skipped.Children = types.Images{skippedChild}
+	images := types.Images{base, tools, skipped}
+
+	mutex := sync.Mutex{}
+	finished := map[string]bool{}
+	err := ScheduleBuilds(ctx, images, func(image *types.Image) error {
+		mutex.Lock()
+		if image.Name == "child" {
+			assert.True(t, finished["base"])
+			assert.True(t, finished["tools"])
+		}
+		mutex.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mutex.Lock()
+		finished[image.Name] = true
+		mutex.Unlock()
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"base": true, "tools": true, "child": true, "skipped-child": true}, finished)
+	assert.Equal(t, types.BuildStatusBuilt, child.BuildResult.Status)
+	assert.GreaterOrEqual(t, child.BuildResult.Duration, 5*time.Millisecond)
+	assert.Nil(t, skipped.BuildResult)
+}
+
+func TestScheduleBuilds_SuccessJobsLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		jobs    int
+		wantMax int32
+	}{
+		{name: "Parallel", jobs: 2, wantMax: 2},
+		{name: "DefaultSerial", jobs: 0, wantMax: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			ctx.Config.Build.Jobs = tt.jobs
+			images := types.Images{}
+			for _, name := range []string{"a", "b", "c", "d", "e"} {
+				images = append(images, &types.Image{ImageName: types.ImageName{Name: name, Tag: "0.1"}, Path: "/app/" + name, HasToBuild: true})
+			}
+			var current, max, count int32
+			err := ScheduleBuilds(ctx, images, func(image *types.Image) error {
+				value := atomic.AddInt32(&current, 1)
+				for {
+					previous := atomic.LoadInt32(&max)
+					if value <= previous || atomic.CompareAndSwapInt32(&max, previous, value) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&current, -1)
+				atomic.AddInt32(&count, 1)
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, int32(5), count)
+			assert.Equal(t, tt.wantMax, max)
+		})
+	}
+}
+
+func TestScheduleBuilds_ErrorCancelDescendants(t *testing.T) {
+	b := bytes.NewBufferString("")
+	ctx := context.TestContext(b)
+	ctx.Config.Build.Jobs = 2
+	failed := &types.Image{ImageName: types.ImageName{Name: "failed", Tag: "0.1"}, Path: "/app/failed", HasToBuild: true}
+	child := &types.Image{ImageName: types.ImageName{Name: "child", Tag: "0.1"}, Path: "/app/child", HasLocalParent: true, Parent: failed, HasToBuild: true}
+	grandChild := &types.Image{ImageName: types.ImageName{Name: "grand-child", Tag: "0.1"}, Path: "/app/grand-child", HasLocalParent: true, Parent: child, HasToBuild: true}
+	child.Children = types.Images{grandChild}
+	failed.Children = types.Images{child}
+	running := &types.Image{ImageName: types.ImageName{Name: "running", Tag: "0.1"}, Path: "/app/running", HasToBuild: true}
+	images := types.Images{failed, running}
+
+	failedDone := make(chan struct{})
+	mutex := sync.Mutex{}
+	built := []string{}
+	err := ScheduleBuilds(ctx, images, func(image *types.Image) error {
+		if image.Name == "failed" {
+			close(failedDone)
+			return errors.New("error")
+		}
+		<-failedDone
+		mutex.Lock()
+		built = append(built, image.Name)
+		mutex.Unlock()
+		return nil
+	})
+	assert.EqualError(t, err, "fail to build failed:0.1 with error: error")
+	assert.Equal(t, []string{"running"}, built)
+	assert.Contains(t, b.String(), "Cancel building child:0.1, failed:0.1 failed")
+	assert.Contains(t, b.String(), "Cancel building grand-child:0.1, failed:0.1 failed")
+}
+
+func TestScheduleBuilds_ErrorStopStartingBuilds(t *testing.T) {
+	ctx := context.TestContext(nil)
+	images := types.Images{
+		&types.Image{ImageName: types.ImageName{Name: "failed", Tag: "0.1"}, Path: "/app/failed", HasToBuild: true},
+		&types.Image{ImageName: types.ImageName{Name: "other", Tag: "0.1"}, Path: "/app/other", HasToBuild: true},
+	}
+	built := []string{}
+	err := ScheduleBuilds(ctx, images, func(image *types.Image) error {
+		if image.Name == "failed" {
+			return errors.New("error")
+		}
+		built = append(built, image.Name)
+		return nil
+	})
+	assert.EqualError(t, err, "fail to build failed:0.1 with error: error")
+	assert.Empty(t, built)
+	assert.Equal(t, types.BuildStatusFailed, images[0].BuildResult.Status)
+	assert.Equal(t, types.BuildStatusSkipped, images[1].BuildResult.Status)
+	assert.False(t, images[1].IsBuilt)
+}
+
+func TestScheduleBuilds_ErrorKeepGoing(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.Config.Build.KeepGoing = true
+	failed := &types.Image{ImageName: types.ImageName{Name: "failed", Tag: "0.1"}, Path: "/app/failed", HasToBuild: true}
+	child := &types.Image{ImageName: types.ImageName{Name: "child", Tag: "0.1"}, Path: "/app/child", HasLocalParent: true, Parent: failed, HasToBuild: true}
+	failed.Children = types.Images{child}
+	other := &types.Image{ImageName: types.ImageName{Name: "other", Tag: "0.1"}, Path: "/app/other", HasToBuild: true}
+	otherChild := &types.Image{ImageName: types.ImageName{Name: "other-child", Tag: "0.1"}, Path: "/app/other-child", HasLocalParent: true, Parent: other, HasToBuild: true}
+	other.Children = types.Images{otherChild}
+	dependent := &types.Image{ImageName: types.ImageName{Name: "dependent", Tag: "0.1"}, Path: "/app/dependent", Dependencies: types.Images{child}, HasToBuild: true}
+	failedToo := &types.Image{ImageName: types.ImageName{Name: "failed-too", Tag: "0.1"}, Path: "/app/failed-too", HasToBuild: true}
+	images := types.Images{failed, other, dependent, failedToo}
+
+	err := ScheduleBuilds(ctx, images, func(image *types.Image) error {
+		if image.Name == "failed" || image.Name == "failed-too" {
+			return errors.New("error")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "fail to build 2 images: failed:0.1, failed-too:0.1")
+	assert.Equal(t, types.BuildStatusFailed, failed.BuildResult.Status)
+	assert.EqualError(t, failed.BuildResult.Err, "error")
+	assert.Equal(t, types.BuildStatusCancelled, child.BuildResult.Status)
+	assert.Equal(t, failed, child.BuildResult.FailedImage)
+	assert.Equal(t, types.BuildStatusCancelled, dependent.BuildResult.Status)
+	assert.Equal(t, failed, dependent.BuildResult.FailedImage)
+	assert.Equal(t, types.BuildStatusBuilt, other.BuildResult.Status)
+	assert.Equal(t, types.BuildStatusBuilt, otherChild.BuildResult.Status)
+	assert.True(t, otherChild.IsBuilt)
+	assert.False(t, child.IsBuilt)
+}
@@ -3,6 +3,7 @@ package context
 import (
 	"github.com/alexandreh2ag/mib/config"
 	typesContainers "github.com/alexandreh2ag/mib/types/container"
+	typesState "github.com/alexandreh2ag/mib/types/state"
 	"github.com/spf13/afero"
 	"io"
 	"log/slog"
@@ -16,6 +17,10 @@ type Context struct {
 	LogLevel   *slog.LevelVar
 	FS         afero.Fs
 	Builders   typesContainers.Builders
+	StateStore typesState.Store
+	// RepoDir is the root dir of the git repository, watch globs are relative to it. It is detected from the working
+	// dir when empty.
+	RepoDir string
 }
 
 func NewContext(config *config.Config, workingDir string, logger *slog.Logger, logLevel *slog.LevelVar, FSProvider afero.Fs) *Context {
@@ -31,7 +36,7 @@ func DefaultContext() *Context {
 		panic(err)
 	}
 	cfg := config.DefaultConfig()
-	return NewContext(&cfg, workingDir, slog.New(slog.NewTextHandler(os.Stdout, opts)), level, afero.NewOsFs())
+	return NewContext(&cfg, workingDir, slog.New(slog.NewTextHandler(os.Stderr, opts)), level, afero.NewOsFs())
 }
 
 func TestContext(logBuffer io.Writer) *Context {
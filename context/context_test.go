@@ -37,7 +37,7 @@ func TestDefaultContext(t *testing.T) {
 	level := &slog.LevelVar{}
 	level.Set(slog.LevelInfo)
 	opts := &slog.HandlerOptions{AddSource: false, Level: level}
-	logger := slog.New(slog.NewTextHandler(os.Stdout, opts))
+	logger := slog.New(slog.NewTextHandler(os.Stderr, opts))
 	cfg := config.DefaultConfig()
 	want := &Context{
 		Config:     &cfg,
@@ -0,0 +1,284 @@
+package dockerfile
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	Scratch = "scratch"
+
+	instructionArg  = "ARG"
+	instructionFrom = "FROM"
+	instructionCopy = "COPY"
+	instructionRun  = "RUN"
+	flagPlatform    = "--platform="
+	flagFrom        = "--from="
+	flagMount       = "--mount="
+	mountFrom       = "from="
+)
+
+type Stage struct {
+	Index    int
+	Name     string
+	From     string
+	Platform string
+	// Sources holds the stages or images referenced by COPY --from and RUN --mount=from.
+	Sources []string
+}
+
+type Dockerfile struct {
+	// Args holds the values of the ARG declared before the first FROM, the only ones usable in FROM.
+	Args   map[string]string
+	Stages []*Stage
+}
+
+// Parse reads the Dockerfile content. buildArgs override the default value of
+// the global ARG, like --build-arg does, before FROM lines are expanded.
+func Parse(content string, buildArgs map[string]string) (*Dockerfile, error) {
+	dockerfile := &Dockerfile{Args: map[string]string{}}
+	for _, instruction := range getInstructions(content) {
+		fields := strings.Fields(instruction)
+		keyword := strings.ToUpper(fields[0])
+		if keyword == instructionArg && len(dockerfile.Stages) == 0 {
+			parseArg(dockerfile.Args, fields[1:], buildArgs)
+			continue
+		}
+		if keyword == instructionFrom {
+			stage, err := parseFrom(fields[1:])
+			if err != nil {
+				return nil, err
+			}
+			stage.From = Expand(stage.From, dockerfile.Args)
+			stage.Index = len(dockerfile.Stages)
+			dockerfile.Stages = append(dockerfile.Stages, stage)
+			continue
+		}
+
+		if len(dockerfile.Stages) == 0 {
+			continue
+		}
+		current := dockerfile.Stages[len(dockerfile.Stages)-1]
+		switch keyword {
+		case instructionCopy:
+			current.Sources = append(current.Sources, parseCopySources(fields[1:])...)
+		case instructionRun:
+			current.Sources = append(current.Sources, parseMountSources(fields[1:])...)
+		}
+	}
+
+	if len(dockerfile.Stages) == 0 {
+		return nil, errors.New("no FROM instruction found")
+	}
+
+	return dockerfile, nil
+}
+
+func parseFrom(args []string) (*Stage, error) {
+	stage := &Stage{}
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		if strings.HasPrefix(strings.ToLower(args[0]), flagPlatform) {
+			stage.Platform = args[0][len(flagPlatform):]
+		}
+		args = args[1:]
+	}
+
+	switch {
+	case len(args) == 1:
+		stage.From = args[0]
+	case len(args) == 3 && strings.EqualFold(args[1], "AS"):
+		stage.From = args[0]
+		stage.Name = strings.ToLower(args[2])
+	default:
+		return nil, fmt.Errorf("invalid FROM instruction: FROM %s", strings.Join(args, " "))
+	}
+
+	return stage, nil
+}
+
+func parseArg(args map[string]string, fields []string, buildArgs map[string]string) {
+	for _, field := range fields {
+		name, value, _ := strings.Cut(field, "=")
+		if buildArg, ok := buildArgs[name]; ok {
+			args[name] = buildArg
+			continue
+		}
+		args[name] = Expand(strings.Trim(value, `"'`), args)
+	}
+}
+
+// Expand replaces $VAR, ${VAR}, ${VAR:-default} and ${VAR:+value} like the
+// Dockerfile frontend does. Unknown variables are replaced by an empty string.
+func Expand(value string, args map[string]string) string {
+	result := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == '$':
+			result.WriteByte('$')
+			i++
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '{':
+			end := strings.IndexByte(value[i:], '}')
+			if end == -1 {
+				result.WriteString(value[i:])
+				return result.String()
+			}
+			result.WriteString(expandBraces(value[i+2:i+end], args))
+			i += end
+		case value[i] == '$':
+			end := i + 1
+			for end < len(value) && isNameChar(value[end]) {
+				end++
+			}
+			if end == i+1 {
+				result.WriteByte('$')
+				continue
+			}
+			result.WriteString(args[value[i+1:end]])
+			i = end - 1
+		default:
+			result.WriteByte(value[i])
+		}
+	}
+	return result.String()
+}
+
+func expandBraces(expression string, args map[string]string) string {
+	if name, word, found := strings.Cut(expression, ":-"); found {
+		if args[name] == "" {
+			return Expand(word, args)
+		}
+		return args[name]
+	}
+	if name, word, found := strings.Cut(expression, ":+"); found {
+		if args[name] != "" {
+			return Expand(word, args)
+		}
+		return ""
+	}
+	return args[expression]
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func parseCopySources(args []string) []string {
+	sources := []string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(arg), flagFrom) {
+			sources = append(sources, arg[len(flagFrom):])
+		}
+	}
+	return sources
+}
+
+func parseMountSources(args []string) []string {
+	sources := []string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			break
+		}
+		if !strings.HasPrefix(strings.ToLower(arg), flagMount) {
+			continue
+		}
+		for _, option := range strings.Split(arg[len(flagMount):], ",") {
+			if strings.HasPrefix(strings.ToLower(option), mountFrom) {
+				sources = append(sources, option[len(mountFrom):])
+			}
+		}
+	}
+	return sources
+}
+
+func getInstructions(content string) []string {
+	instructions := []string{}
+	current := ""
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") {
+			current += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		instructions = append(instructions, current+line)
+		current = ""
+	}
+	if strings.TrimSpace(current) != "" {
+		instructions = append(instructions, current)
+	}
+
+	return instructions
+}
+
+func (d Dockerfile) GetStage(name string) *Stage {
+	for _, stage := range d.Stages {
+		if stage.Name != "" && stage.Name == strings.ToLower(name) {
+			return stage
+		}
+	}
+	return nil
+}
+
+func (d Dockerfile) FinalStage() *Stage {
+	return d.Stages[len(d.Stages)-1]
+}
+
+// IsExternal reports whether the stage starts from an image rather than
+// from a previous stage or from scratch.
+func (d Dockerfile) IsExternal(stage *Stage) bool {
+	return d.isExternalRef(stage, stage.From)
+}
+
+func (d Dockerfile) isExternalRef(stage *Stage, ref string) bool {
+	if strings.EqualFold(ref, Scratch) {
+		return false
+	}
+	previous := d.GetStage(ref)
+	return previous == nil || previous.Index >= stage.Index
+}
+
+// isExternalSource is like isExternalRef but also accepts a stage index, as
+// COPY --from and RUN --mount=from do.
+func (d Dockerfile) isExternalSource(stage *Stage, source string) bool {
+	if _, err := strconv.Atoi(source); err == nil {
+		return false
+	}
+	return d.isExternalRef(stage, source)
+}
+
+// BaseImage follows internal stage references and returns the external image
+// the stage is built on, or an empty string when it starts from scratch.
+func (d Dockerfile) BaseImage(stage *Stage) string {
+	for !d.IsExternal(stage) {
+		if strings.EqualFold(stage.From, Scratch) {
+			return ""
+		}
+		stage = d.GetStage(stage.From)
+	}
+	return stage.From
+}
+
+// ExternalImages returns every image used by a stage, either as base or as
+// source of COPY --from and RUN --mount=from, in order of appearance.
+func (d Dockerfile) ExternalImages() []string {
+	images := []string{}
+	for _, stage := range d.Stages {
+		if d.IsExternal(stage) && !slices.Contains(images, stage.From) {
+			images = append(images, stage.From)
+		}
+		for _, source := range stage.Sources {
+			if d.isExternalSource(stage, source) && !slices.Contains(images, source) {
+				images = append(images, source)
+			}
+		}
+	}
+	return images
+}
@@ -0,0 +1,165 @@
+package dockerfile
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *Dockerfile
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "SuccessSingleStage",
+			content: "FROM debian:latest\nRUN echo foo",
+			want:    &Dockerfile{Args: map[string]string{}, Stages: []*Stage{{Index: 0, From: "debian:latest"}}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "SuccessMultiStage",
+			content: "# syntax=docker/dockerfile:1\nfrom --platform=$BUILDPLATFORM golang:1.22 as Builder\nRUN go build \\\n  ./...\nFROM builder AS test\nFROM alpine:3.19\nCOPY --from=builder /app /app",
+			want: &Dockerfile{Args: map[string]string{}, Stages: []*Stage{
+				{Index: 0, Name: "builder", From: "golang:1.22", Platform: "$BUILDPLATFORM"},
+				{Index: 1, Name: "test", From: "builder"},
+				{Index: 2, From: "alpine:3.19", Sources: []string{"builder"}},
+			}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "SuccessFromWithContinuation",
+			content: "FROM \\\n  debian:latest \\\n  AS base",
+			want:    &Dockerfile{Args: map[string]string{}, Stages: []*Stage{{Index: 0, Name: "base", From: "debian:latest"}}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "SuccessWithSources",
+			content: "FROM debian:latest\nCOPY --chown=app --from=registry/tools:1.2 /bin/tool /bin/tool\nRUN --mount=type=cache,target=/cache \\\n  --mount=type=bind,from=registry/assets:0.1,target=/assets echo foo\nCOPY ./file --from=ignored:1 /file",
+			want:    &Dockerfile{Args: map[string]string{}, Stages: []*Stage{{Index: 0, From: "debian:latest", Sources: []string{"registry/tools:1.2", "registry/assets:0.1"}}}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "SuccessWithGlobalArgs",
+			content: "ARG BASE_IMAGE=alpine\nARG BASE_TAG=\"3.19\" UNUSED\nFROM ${BASE_IMAGE}:$BASE_TAG AS base\nARG BASE_TAG=3.20\nFROM base",
+			want: &Dockerfile{
+				Args:   map[string]string{"BASE_IMAGE": "alpine", "BASE_TAG": "3.19", "UNUSED": ""},
+				Stages: []*Stage{{Index: 0, Name: "base", From: "alpine:3.19"}, {Index: 1, From: "base"}},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "FailNoFrom",
+			content: "# FROM debian:latest\nRUN echo foo",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "FailInvalidFrom",
+			content: "FROM debian:latest base",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.content, nil)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse_WithBuildArgs(t *testing.T) {
+	content := "ARG REGISTRY=docker.io\nARG BASE_TAG=3.19\nARG FULL_TAG=${BASE_TAG}-slim\nFROM ${REGISTRY}/library/debian:${FULL_TAG}"
+	got, err := Parse(content, map[string]string{"BASE_TAG": "3.20", "NOT_DECLARED": "foo"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"REGISTRY": "docker.io", "BASE_TAG": "3.20", "FULL_TAG": "3.20-slim"}, got.Args)
+	assert.Equal(t, "docker.io/library/debian:3.20-slim", got.FinalStage().From)
+}
+
+func TestExpand(t *testing.T) {
+	args := map[string]string{"NAME": "alpine", "TAG": "3.19", "EMPTY": ""}
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "SuccessNoVariable", value: "alpine:3.19", want: "alpine:3.19"},
+		{name: "SuccessSimple", value: "$NAME:$TAG", want: "alpine:3.19"},
+		{name: "SuccessBraces", value: "${NAME}:${TAG}-slim", want: "alpine:3.19-slim"},
+		{name: "SuccessDefault", value: "${NAME}:${EMPTY:-latest}", want: "alpine:latest"},
+		{name: "SuccessDefaultNotUsed", value: "${NAME}:${TAG:-latest}", want: "alpine:3.19"},
+		{name: "SuccessAlternative", value: "${NAME}${TAG:+:$TAG}", want: "alpine:3.19"},
+		{name: "SuccessAlternativeNotUsed", value: "${NAME}${EMPTY:+:$TAG}", want: "alpine"},
+		{name: "SuccessUnknown", value: "${NAME}:${UNKNOWN}", want: "alpine:"},
+		{name: "SuccessEscaped", value: "\\$NAME", want: "$NAME"},
+		{name: "SuccessUnclosedBrace", value: "${NAME", want: "${NAME"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Expand(tt.value, args))
+		})
+	}
+}
+
+func TestDockerfile_BaseImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "SuccessSingleStage",
+			content: "FROM debian:latest",
+			want:    "debian:latest",
+		},
+		{
+			name:    "SuccessFinalStageFromInternalStage",
+			content: "FROM debian:latest AS base\nFROM golang:1.22 AS builder\nFROM base",
+			want:    "debian:latest",
+		},
+		{
+			name:    "SuccessFinalStageFromScratch",
+			content: "FROM golang:1.22 AS builder\nFROM scratch",
+			want:    "",
+		},
+		{
+			name:    "SuccessStageNameUsedBeforeDeclaration",
+			content: "FROM base AS first\nFROM debian:latest AS base\nFROM first",
+			want:    "base",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := Parse(tt.content, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, df.BaseImage(df.FinalStage()))
+		})
+	}
+}
+
+func TestDockerfile_ExternalImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "SuccessStages",
+			content: "FROM golang:1.22 AS builder\nFROM builder AS test\nFROM golang:1.22 AS lint\nFROM scratch AS empty\nFROM alpine:3.19",
+			want:    []string{"golang:1.22", "alpine:3.19"},
+		},
+		{
+			name:    "SuccessSources",
+			content: "FROM golang:1.22 AS builder\nFROM alpine:3.19\nCOPY --from=builder /app /app\nCOPY --from=0 /app /app\nCOPY --from=registry/tools:1.2 /tool /tool\nRUN --mount=from=golang:1.22,target=/go echo",
+			want:    []string{"golang:1.22", "alpine:3.19", "registry/tools:1.2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df, err := Parse(tt.content, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, df.ExternalImages())
+		})
+	}
+}
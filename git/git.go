@@ -1,19 +1,26 @@
 package git
 
 import (
+	"container/heap"
+	"encoding/hex"
 	"fmt"
 	"github.com/alexandreh2ag/mib/context"
 	"github.com/alexandreh2ag/mib/loader"
 	"github.com/alexandreh2ag/mib/types"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 	"io"
+	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
+	"time"
 )
 
 const (
@@ -35,6 +42,7 @@ type Worktree interface {
 	Status() (git.Status, error)
 	AddWithOptions(opts *git.AddOptions) error
 	Commit(msg string, opts *git.CommitOptions) (plumbing.Hash, error)
+	Submodules() (git.Submodules, error)
 }
 
 type Manager interface {
@@ -44,14 +52,60 @@ type Manager interface {
 	CommitFileContent(hash *plumbing.Hash, path string) (string, error)
 	AddWithOptions(opts *git.AddOptions) error
 	CreateCommit(msg string, opts *git.CommitOptions) (plumbing.Hash, error)
-	GetCommitFilesChanged(hash string) ([]string, error)
+	GetCommitFilesChanged(revision string, allParents bool) ([]string, error)
+	GetRangeFilesChanged(from string, to string) ([]string, error)
+	GetBranchFilesChanged(base string) ([]string, error)
+	GetCommitImagesRemoved(revision string, allParents bool) (types.ImagesRemoved, error)
+	GetSubmodulesFilesChanged() ([]string, error)
+	ExportCommit(hash string, fs afero.Fs, dir string) error
+	RootDir() string
 }
 
 var _ Manager = &Git{}
 
+// Git paths are relative to the mib working dir, which can be a subdirectory of the repository root.
 type Git struct {
-	r Repository
-	w Worktree
+	r          Repository
+	w          Worktree
+	root       string
+	workingDir string
+}
+
+// RootDir returns the root dir of the repository worktree.
+func (g Git) RootDir() string {
+	return g.root
+}
+
+// fromRepoPath translates a path relative to the repository root into a path relative to the working dir.
+func (g Git) fromRepoPath(path string) string {
+	if g.root == g.workingDir {
+		return path
+	}
+	rel, err := filepath.Rel(g.workingDir, filepath.Join(g.root, filepath.FromSlash(path)))
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// toRepoPath translates a path relative to the working dir into a path relative to the repository root.
+func (g Git) toRepoPath(path string) string {
+	if g.root == g.workingDir {
+		return path
+	}
+	rel, err := filepath.Rel(g.root, filepath.Join(g.workingDir, filepath.FromSlash(path)))
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (g Git) fromRepoPaths(paths []string) []string {
+	translated := []string{}
+	for _, path := range paths {
+		translated = append(translated, g.fromRepoPath(path))
+	}
+	return translated
 }
 
 func (g Git) Head() (string, error) {
@@ -59,7 +113,7 @@ func (g Git) Head() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return head.String(), nil
+	return head.Hash().String(), nil
 }
 
 func (g Git) CreateCommit(msg string, opts *git.CommitOptions) (plumbing.Hash, error) {
@@ -67,7 +121,18 @@ func (g Git) CreateCommit(msg string, opts *git.CommitOptions) (plumbing.Hash, e
 }
 
 func (g Git) Status() (git.Status, error) {
-	return g.w.Status()
+	status, err := g.w.Status()
+	if err != nil || g.root == g.workingDir {
+		return status, err
+	}
+	translated := git.Status{}
+	for path, fileStatus := range status {
+		if fileStatus.Extra != "" {
+			fileStatus.Extra = g.fromRepoPath(fileStatus.Extra)
+		}
+		translated[g.fromRepoPath(path)] = fileStatus
+	}
+	return translated, nil
 }
 
 func (g Git) ResolveRevision(in plumbing.Revision) (*plumbing.Hash, error) {
@@ -80,7 +145,7 @@ func (g Git) CommitFileContent(hash *plumbing.Hash, path string) (string, error)
 		return "", err
 	}
 
-	file, err := commitObject.File(path)
+	file, err := commitObject.File(g.toRepoPath(path))
 	if err != nil {
 		return "", err
 	}
@@ -88,61 +153,680 @@ func (g Git) CommitFileContent(hash *plumbing.Hash, path string) (string, error)
 }
 
 func (g Git) AddWithOptions(opts *git.AddOptions) error {
+	if opts.Path != "" {
+		repoOpts := *opts
+		repoOpts.Path = g.toRepoPath(opts.Path)
+		opts = &repoOpts
+	}
 	return g.w.AddWithOptions(opts)
 }
 
-func (g Git) GetCommitFilesChanged(hash string) ([]string, error) {
-	var commit *object.Commit
-	var err error
+var shortHashRegexp = regexp.MustCompile(`^[0-9a-fA-F]{4,39}$`)
+
+// getCommit resolves any revision (sha, short sha, tag, branch, HEAD~1...) to its commit.
+func (g Git) getCommit(revision string) (*object.Commit, error) {
+	errAmbiguous := g.checkAmbiguousHash(revision)
+	if errAmbiguous != nil {
+		return nil, errAmbiguous
+	}
+	hash, err := g.r.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("commit not found for revision %s: %v", revision, err)
+	}
+	commit, err := g.r.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("commit not found for revision %s: %v", revision, err)
+	}
+	return commit, nil
+}
+
+// checkAmbiguousHash returns an error when the revision starts with a short hash
+// matching several commits, since ResolveRevision silently picks one of them.
+func (g Git) checkAmbiguousHash(revision string) error {
+	shortHash := revision
+	if i := strings.IndexAny(revision, "~^@:"); i >= 0 {
+		shortHash = revision[:i]
+	}
+	if !shortHashRegexp.MatchString(shortHash) {
+		return nil
+	}
+	candidates, err := g.commitsWithPrefix(strings.ToLower(shortHash))
+	if err != nil {
+		return err
+	}
+	if len(candidates) > 1 {
+		slices.Sort(candidates)
+		return fmt.Errorf("short hash %s is ambiguous, candidates are: %s", shortHash, strings.Join(candidates, ", "))
+	}
+	return nil
+}
+
+// hashPrefixStorer is implemented by storers able to look objects up by hash prefix without reading every object.
+type hashPrefixStorer interface {
+	HashesWithPrefix(prefix []byte) ([]plumbing.Hash, error)
+}
+
+// commitsWithPrefix returns the hashes of the commits starting with the lowercase hex prefix, it only falls back
+// to iterating all commits when the storer cannot look the prefix up.
+func (g Git) commitsWithPrefix(prefix string) ([]string, error) {
+	candidates := []string{}
+	if repo, ok := g.r.(*git.Repository); ok {
+		if storer, ok := repo.Storer.(hashPrefixStorer); ok {
+			prefixBytes, err := hex.DecodeString(prefix[:len(prefix)/2*2])
+			if err != nil {
+				return nil, err
+			}
+			hashes, err := storer.HashesWithPrefix(prefixBytes)
+			if err != nil {
+				return nil, err
+			}
+			for _, hash := range hashes {
+				if !strings.HasPrefix(hash.String(), prefix) || slices.Contains(candidates, hash.String()) {
+					continue
+				}
+				if _, errCommit := g.r.CommitObject(hash); errCommit == nil {
+					candidates = append(candidates, hash.String())
+				}
+			}
+			return candidates, nil
+		}
+	}
+	commits, err := g.r.CommitObjects()
+	if err != nil {
+		return nil, err
+	}
+	err = commits.ForEach(func(c *object.Commit) error {
+		if strings.HasPrefix(c.Hash.String(), prefix) {
+			candidates = append(candidates, c.Hash.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// GetCommitFilesChanged returns files changed by the commit compared with its first parent,
+// or with each of its parents when allParents is true.
+func (g Git) GetCommitFilesChanged(revision string, allParents bool) ([]string, error) {
+	commit, err := g.getCommit(revision)
+	if err != nil {
+		return nil, err
+	}
+	files, err := g.commitFilesChanged(commit, allParents)
+	if err != nil {
+		return nil, err
+	}
+	return g.fromRepoPaths(files), nil
+}
+
+// GetRangeFilesChanged returns the union of files changed by the commits reachable
+// from the revision to but not from the revision from, like git log from..to.
+func (g Git) GetRangeFilesChanged(from string, to string) ([]string, error) {
+	fromCommit, err := g.getCommit(from)
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := g.getCommit(to)
+	if err != nil {
+		return nil, err
+	}
+
+	flags, commits, err := paintCommits(fromCommit, toCommit)
+	if err != nil {
+		return nil, err
+	}
+
 	files := []string{}
-	if len(hash) < 40 {
-		cl, _ := g.r.CommitObjects()
-		_ = cl.ForEach(func(c *object.Commit) error {
-			if c.Hash.String()[:len(hash)] == hash {
-				commit = c
-				cl.Close()
+	for hash, flag := range flags {
+		if flag&reachFrom != 0 {
+			continue
+		}
+		changedFiles, errCommit := g.commitFilesChanged(commits[hash], false)
+		if errCommit != nil {
+			return nil, fmt.Errorf("fail to get files changed of commit %s: %v", hash, errCommit)
+		}
+		for _, file := range changedFiles {
+			if !slices.Contains(files, file) {
+				files = append(files, file)
 			}
+		}
+	}
+	files = g.fromRepoPaths(files)
+	slices.Sort(files)
+	return files, nil
+}
+
+const (
+	reachFrom = 1 << iota
+	reachTo
+	reachStale
+	reachBoth = reachFrom | reachTo
+)
+
+// paintCommits flags the ancestors of from and to with the sides they are reachable from, commits reachable from
+// a common ancestor are also flagged as stale. Commits are walked newest first and the walk stops once every pending
+// commit is reachable from from and older than the commits only reachable from to, so only the commits down to the
+// merge-bases are visited like git does.
+func paintCommits(from *object.Commit, to *object.Commit) (map[plumbing.Hash]int, map[plumbing.Hash]*object.Commit, error) {
+	flags := map[plumbing.Hash]int{}
+	commits := map[plumbing.Hash]*object.Commit{}
+	queue := &commitQueue{}
+	paint := func(c *object.Commit, flag int) {
+		if flags[c.Hash]&flag == flag {
+			return
+		}
+		flags[c.Hash] |= flag
+		commits[c.Hash] = c
+		heap.Push(queue, c)
+	}
+	paint(from, reachFrom)
+	paint(to, reachTo)
+	var oldestTo *time.Time
+	for !queue.done(flags, oldestTo) {
+		current := heap.Pop(queue).(*object.Commit)
+		flag := flags[current.Hash]
+		if flag&reachFrom == 0 && (oldestTo == nil || current.Committer.When.Before(*oldestTo)) {
+			oldestTo = &current.Committer.When
+		}
+		if flag&reachBoth == reachBoth {
+			flag |= reachStale
+		}
+		err := current.Parents().ForEach(func(parent *object.Commit) error {
+			paint(parent, flag)
 			return nil
 		})
-	} else {
-		commit, _ = g.r.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return flags, commits, nil
+}
+
+// mergeBase returns the newest common ancestor of from and to which is not an ancestor of another common ancestor.
+func mergeBase(from *object.Commit, to *object.Commit) (*object.Commit, error) {
+	flags, commits, err := paintCommits(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var base *object.Commit
+	for hash, flag := range flags {
+		if flag&(reachBoth|reachStale) != reachBoth {
+			continue
+		}
+		c := commits[hash]
+		if base == nil || c.Committer.When.After(base.Committer.When) ||
+			(c.Committer.When.Equal(base.Committer.When) && c.Hash.String() < base.Hash.String()) {
+			base = c
+		}
 	}
+	return base, nil
+}
+
+// commitQueue is a heap of commits ordered by committer date, newest first, then by insertion order.
+type commitQueue struct {
+	commits []*object.Commit
+	order   []int
+	count   int
+}
 
-	if commit == nil {
-		return nil, fmt.Errorf("commit not found")
+func (q *commitQueue) Len() int { return len(q.commits) }
+
+func (q *commitQueue) Less(i, j int) bool {
+	if !q.commits[i].Committer.When.Equal(q.commits[j].Committer.When) {
+		return q.commits[i].Committer.When.After(q.commits[j].Committer.When)
 	}
+	return q.order[i] < q.order[j]
+}
 
-	parentCommit, errParent := commit.Parents().Next()
-	if errParent != nil {
-		if errParent == io.EOF {
-			fl, _ := commit.Files()
-			_ = fl.ForEach(func(f *object.File) error {
-				files = append(files, f.Name)
-				return nil
-			})
-		} else {
-			return files, err
-		}
-	} else {
-		patch, _ := parentCommit.Patch(commit)
-		for _, filePatch := range patch.FilePatches() {
-			from, to := filePatch.Files()
-			if from == nil {
-				files = append(files, to.Path())
-			} else if to == nil {
-				files = append(files, from.Path())
-			} else if from.Path() != to.Path() {
-				files = append(files, to.Path())
-			} else {
-				files = append(files, from.Path())
+func (q *commitQueue) Swap(i, j int) {
+	q.commits[i], q.commits[j] = q.commits[j], q.commits[i]
+	q.order[i], q.order[j] = q.order[j], q.order[i]
+}
+
+func (q *commitQueue) Push(x any) {
+	q.commits = append(q.commits, x.(*object.Commit))
+	q.order = append(q.order, q.count)
+	q.count++
+}
+
+func (q *commitQueue) Pop() any {
+	last := len(q.commits) - 1
+	c := q.commits[last]
+	q.commits = q.commits[:last]
+	q.order = q.order[:last]
+	return c
+}
+
+// done returns true when every queued commit is reachable from the from side, is not an unwalked common ancestor
+// and is older than the oldest commit walked as only reachable from to, as none of them can change the flags of
+// the walked commits anymore.
+func (q *commitQueue) done(flags map[plumbing.Hash]int, oldestTo *time.Time) bool {
+	if len(q.commits) == 0 {
+		return true
+	}
+	for _, c := range q.commits {
+		flag := flags[c.Hash]
+		if flag&reachFrom == 0 || flag&(reachBoth|reachStale) == reachBoth {
+			return false
+		}
+	}
+	return oldestTo == nil || q.commits[0].Committer.When.Before(*oldestTo)
+}
+
+func (g Git) commitFilesChanged(commit *object.Commit, allParents bool) ([]string, error) {
+	files := []string{}
+	if commit.NumParents() == 0 {
+		return commitFiles(commit)
+	}
+
+	for i := 0; i < commit.NumParents(); i++ {
+		parentCommit, err := commit.Parent(i)
+		if err != nil {
+			return nil, fmt.Errorf("fail to get parent of commit %s: %v", commit.Hash, err)
+		}
+		parentFiles, err := g.diffFiles(parentCommit, commit)
+		if err != nil {
+			return nil, fmt.Errorf("fail to diff commit %s with %s: %v", commit.Hash, parentCommit.Hash, err)
+		}
+		for _, file := range parentFiles {
+			if !slices.Contains(files, file) {
+				files = append(files, file)
 			}
 		}
+		if !allParents {
+			break
+		}
+	}
+	return files, nil
+}
+
+func commitFiles(commit *object.Commit) ([]string, error) {
+	files := []string{}
+	fl, err := commit.Files()
+	if err != nil {
+		return nil, fmt.Errorf("fail to list files of commit %s: %v", commit.Hash, err)
+	}
+	err = fl.ForEach(func(f *object.File) error {
+		files = append(files, f.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to list files of commit %s: %v", commit.Hash, err)
+	}
+	return files, nil
+}
+
+func (g Git) diffFiles(fromCommit *object.Commit, toCommit *object.Commit) ([]string, error) {
+	files := []string{}
+	fromTree, err := fromCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := fromTree.Diff(toTree)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		from, to := change.From, change.To
+		if from.TreeEntry.Mode == filemode.Submodule || to.TreeEntry.Mode == filemode.Submodule {
+			files = append(files, g.submoduleFilesChanged(from, to)...)
+			continue
+		}
+		if to.Name != "" {
+			files = append(files, to.Name)
+		} else {
+			files = append(files, from.Name)
+		}
 	}
 	return files, nil
 }
 
+// submoduleFilesChanged expands the change of a submodule pointer into the files changed inside the submodule,
+// the submodule path is kept when the submodule is not initialized or misses one of the commits.
+func (g Git) submoduleFilesChanged(from object.ChangeEntry, to object.ChangeEntry) []string {
+	subPath := to.Name
+	fromHash, toHash := plumbing.ZeroHash, plumbing.ZeroHash
+	if from.TreeEntry.Mode == filemode.Submodule {
+		fromHash = from.TreeEntry.Hash
+		subPath = from.Name
+	}
+	if to.TreeEntry.Mode == filemode.Submodule {
+		toHash = to.TreeEntry.Hash
+		subPath = to.Name
+	}
+	sub, err := g.submodule(subPath)
+	if err != nil {
+		return []string{subPath}
+	}
+	subFiles, err := sub.filesBetween(fromHash, toHash)
+	if err != nil {
+		return []string{subPath}
+	}
+	return prefixPaths(subFiles, subPath)
+}
+
+// filesBetween returns files changed between two commits, all files of the other commit when one is zero.
+func (g Git) filesBetween(from plumbing.Hash, to plumbing.Hash) ([]string, error) {
+	var fromCommit, toCommit *object.Commit
+	var err error
+	if !from.IsZero() {
+		if fromCommit, err = g.r.CommitObject(from); err != nil {
+			return nil, err
+		}
+	}
+	if !to.IsZero() {
+		if toCommit, err = g.r.CommitObject(to); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case fromCommit == nil && toCommit == nil:
+		return []string{}, nil
+	case fromCommit == nil:
+		return commitFiles(toCommit)
+	case toCommit == nil:
+		return commitFiles(fromCommit)
+	}
+	return g.diffFiles(fromCommit, toCommit)
+}
+
+// submodule returns the manager of the initialized submodule at path, relative to the repository root.
+func (g Git) submodule(path string) (*Git, error) {
+	submodules, err := g.w.Submodules()
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range submodules {
+		if sub.Config().Path == path {
+			return submoduleGit(sub)
+		}
+	}
+	return nil, fmt.Errorf("submodule %s not found", path)
+}
+
+func submoduleGit(sub *git.Submodule) (*Git, error) {
+	r, err := sub.Repository()
+	if err != nil {
+		return nil, err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	return &Git{r: r, w: w}, nil
+}
+
+// GetSubmodulesFilesChanged returns files changed inside initialized submodules, between the commit recorded
+// in the repository and the submodule HEAD, and not committed in the submodule.
+func (g Git) GetSubmodulesFilesChanged() ([]string, error) {
+	files := []string{}
+	submodules, err := g.w.Submodules()
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range submodules {
+		status, errStatus := sub.Status()
+		if errStatus != nil {
+			return nil, fmt.Errorf("fail to get status of submodule %s: %v", sub.Config().Path, errStatus)
+		}
+		if status.Current.IsZero() {
+			continue
+		}
+		subGit, errSub := submoduleGit(sub)
+		if errSub != nil {
+			return nil, fmt.Errorf("fail to open submodule %s: %v", sub.Config().Path, errSub)
+		}
+		subFiles := []string{}
+		from := status.Expected
+		if recorded := g.headGitlink(sub.Config().Path); !recorded.IsZero() {
+			from = recorded
+		}
+		if from != status.Current {
+			if subFiles, err = subGit.filesBetween(from, status.Current); err != nil {
+				return nil, fmt.Errorf("fail to diff submodule %s: %v", sub.Config().Path, err)
+			}
+		}
+		subFiles = append(subFiles, GetStageFilesChanged(subGit)...)
+		nestedFiles, errNested := subGit.GetSubmodulesFilesChanged()
+		if errNested != nil {
+			return nil, errNested
+		}
+		subFiles = append(subFiles, nestedFiles...)
+		for _, file := range prefixPaths(subFiles, sub.Config().Path) {
+			if !slices.Contains(files, file) {
+				files = append(files, file)
+			}
+		}
+	}
+	return g.fromRepoPaths(files), nil
+}
+
+// headGitlink returns the commit of the submodule recorded in the HEAD tree, so a pointer change already staged is
+// still compared with HEAD, or a zero hash when HEAD does not record the submodule.
+func (g Git) headGitlink(subPath string) plumbing.Hash {
+	head, err := g.r.Head()
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	commit, err := g.r.CommitObject(head.Hash())
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	entry, err := tree.FindEntry(subPath)
+	if err != nil || entry.Mode != filemode.Submodule {
+		return plumbing.ZeroHash
+	}
+	return entry.Hash
+}
+
+func prefixPaths(paths []string, prefix string) []string {
+	prefixed := []string{}
+	for _, p := range paths {
+		prefixed = append(prefixed, path.Join(prefix, p))
+	}
+	return prefixed
+}
+
+// GetBranchFilesChanged returns files changed between the merge-base of HEAD and the base revision, and HEAD.
+func (g Git) GetBranchFilesChanged(base string) ([]string, error) {
+	baseCommit, err := g.getCommit(base)
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := g.getCommit(string(plumbing.HEAD))
+	if err != nil {
+		return nil, err
+	}
+	mergeBaseCommit, err := mergeBase(baseCommit, headCommit)
+	if err != nil {
+		return nil, fmt.Errorf("fail to find merge-base of %s and HEAD: %v", base, err)
+	}
+	if mergeBaseCommit == nil {
+		return nil, fmt.Errorf("no merge-base found between %s and HEAD", base)
+	}
+	files, err := g.diffFiles(mergeBaseCommit, headCommit)
+	if err != nil {
+		return nil, err
+	}
+	return g.fromRepoPaths(files), nil
+}
+
+// GetCommitImagesRemoved returns images whose mib.yml was deleted or moved by the commit compared with its first parent,
+// or with each of its parents when allParents is true.
+func (g Git) GetCommitImagesRemoved(revision string, allParents bool) (types.ImagesRemoved, error) {
+	commit, err := g.getCommit(revision)
+	if err != nil {
+		return nil, err
+	}
+	removed := types.ImagesRemoved{}
+	for i := 0; i < commit.NumParents(); i++ {
+		parentCommit, errParent := commit.Parent(i)
+		if errParent != nil {
+			return nil, fmt.Errorf("fail to get parent of commit %s: %v", commit.Hash, errParent)
+		}
+		parentRemoved, errRemoved := imagesRemoved(parentCommit, commit)
+		if errRemoved != nil {
+			return nil, errRemoved
+		}
+		for _, image := range parentRemoved {
+			image.Path = g.fromRepoPath(image.Path)
+			if image.IsRenamed() {
+				image.NewPath = g.fromRepoPath(image.NewPath)
+			}
+			if !slices.ContainsFunc(removed, func(ir types.ImageRemoved) bool { return ir.Path == image.Path }) {
+				removed = append(removed, image)
+			}
+		}
+		if !allParents {
+			break
+		}
+	}
+	return removed, nil
+}
+
+func imagesRemoved(fromCommit *object.Commit, toCommit *object.Commit) (types.ImagesRemoved, error) {
+	removed := types.ImagesRemoved{}
+	fromTree, err := fromCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := fromTree.Diff(toTree)
+	if err != nil {
+		return nil, fmt.Errorf("fail to diff commit %s with %s: %v", toCommit.Hash, fromCommit.Hash, err)
+	}
+	for _, change := range changes {
+		fromPath, toPath := change.From.Name, change.To.Name
+		if path.Base(fromPath) != loader.DataFilename || fromPath == toPath {
+			continue
+		}
+		file, errFile := fromCommit.File(fromPath)
+		if errFile != nil {
+			return nil, errFile
+		}
+		content, errContent := file.Contents()
+		if errContent != nil {
+			return nil, errContent
+		}
+		image := &types.Image{}
+		errUnmarshal := yaml.Unmarshal([]byte(content), image)
+		if errUnmarshal != nil {
+			return nil, fmt.Errorf("could not parse removed %s with error : %s", fromPath, errUnmarshal)
+		}
+		imageRemoved := types.ImageRemoved{Names: image.GetNames(), Path: path.Dir(fromPath)}
+		if toPath != "" {
+			imageRemoved.NewPath = path.Dir(toPath)
+		}
+		removed = append(removed, imageRemoved)
+	}
+	return removed, nil
+}
+
+// ExportCommit writes the files of the commit tree in dir, so images can be built from the content of the commit.
+// The whole repository is exported, the working dir content lands in the same subdirectory of dir. Submodules are
+// exported at the commit recorded in the tree, they must be initialized.
+func (g Git) ExportCommit(hash string, fs afero.Fs, dir string) error {
+	commit, err := g.getCommit(hash)
+	if err != nil {
+		return err
+	}
+	return g.exportCommit(commit, fs, dir)
+}
+
+func (g Git) exportCommit(commit *object.Commit, fs afero.Fs, dir string) error {
+	files, err := commit.Files()
+	if err != nil {
+		return err
+	}
+	errFiles := files.ForEach(func(f *object.File) error {
+		path := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if errMkdir := fs.MkdirAll(filepath.Dir(path), 0755); errMkdir != nil {
+			return errMkdir
+		}
+		if linker, ok := fs.(afero.Linker); ok && f.Mode == filemode.Symlink {
+			target, errContent := f.Contents()
+			if errContent != nil {
+				return errContent
+			}
+			return linker.SymlinkIfPossible(target, path)
+		}
+		return exportFile(fs, f, path)
+	})
+	if errFiles != nil {
+		return errFiles
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, errNext := walker.Next()
+		if errNext == io.EOF {
+			return nil
+		}
+		if errNext != nil {
+			return errNext
+		}
+		if entry.Mode != filemode.Submodule {
+			continue
+		}
+		errSub := g.exportSubmodule(name, entry.Hash, fs, filepath.Join(dir, filepath.FromSlash(name)))
+		if errSub != nil {
+			return fmt.Errorf("fail to export submodule %s at %s: %v", name, entry.Hash, errSub)
+		}
+	}
+}
+
+// exportSubmodule writes the files of the submodule at path, relative to the repository root, at the commit hash.
+func (g Git) exportSubmodule(path string, hash plumbing.Hash, fs afero.Fs, dir string) error {
+	sub, err := g.submodule(path)
+	if err != nil {
+		return err
+	}
+	commit, err := sub.r.CommitObject(hash)
+	if err != nil {
+		return err
+	}
+	return sub.exportCommit(commit, fs, dir)
+}
+
+func exportFile(fs afero.Fs, f *object.File, path string) error {
+	perm := os.FileMode(0644)
+	if f.Mode == filemode.Executable {
+		perm = 0755
+	}
+	reader, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	file, err := fs.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(file, reader)
+	return err
+}
+
 var CreateGit = func(ctx *context.Context) (Manager, error) {
-	r, err := git.PlainOpen(ctx.WorkingDir)
+	r, err := git.PlainOpenWithOptions(ctx.WorkingDir, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +837,12 @@ var CreateGit = func(ctx *context.Context) (Manager, error) {
 		return nil, err
 	}
 
-	return Git{r: r, w: w}, nil
+	workingDir, err := filepath.Abs(ctx.WorkingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return Git{r: r, w: w, root: w.Filesystem.Root(), workingDir: workingDir}, nil
 }
 
 func GetStageFilesChanged(m Manager) []string {
@@ -174,6 +863,16 @@ func GetStageFilesChanged(m Manager) []string {
 	return files
 }
 
+// GetDirtyFilesChanged returns files changed in the worktree like GetStageFilesChanged, with the files changed inside submodules.
+func GetDirtyFilesChanged(m Manager) ([]string, error) {
+	files := GetStageFilesChanged(m)
+	submoduleFiles, err := m.GetSubmodulesFilesChanged()
+	if err != nil {
+		return nil, err
+	}
+	return append(files, submoduleFiles...), nil
+}
+
 func GetImagesNameAdded(ctx *context.Context, m Manager) ([]string, error) {
 	names := []string{}
 
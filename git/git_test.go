@@ -6,18 +6,24 @@ import (
 	billyAfero "github.com/Maldris/go-billy-afero"
 	"github.com/alexandreh2ag/mib/context"
 	mockgit "github.com/alexandreh2ag/mib/mock/git"
+	"github.com/alexandreh2ag/mib/types"
 	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/storage/filesystem"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestCreateGit_Success(t *testing.T) {
@@ -29,6 +35,14 @@ func TestCreateGit_Success(t *testing.T) {
 	assert.NotNil(t, got)
 }
 
+func TestCreateGit_SuccessSubdir(t *testing.T) {
+	ctx := context.DefaultContext()
+	root, _ := filepath.Abs(fmt.Sprintf("%s/..", ctx.WorkingDir))
+	got, err := CreateGit(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, root, got.RootDir())
+}
+
 func TestCreateGit_ErrorCreateRpository(t *testing.T) {
 	ctx := context.TestContext(nil)
 	got, err := CreateGit(ctx)
@@ -51,6 +65,25 @@ func TestGit_Status(t *testing.T) {
 	assert.Equal(t, want, got)
 }
 
+func TestGit_Status_SuccessSubdir(t *testing.T) {
+	want := git.Status{
+		"foo/mib.yml":  &git.FileStatus{Worktree: git.Unmodified, Staging: git.Renamed, Extra: "bar/mib.yml"},
+		"../README.md": &git.FileStatus{Worktree: git.Modified, Staging: git.Unmodified},
+	}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	w := mockgit.NewMockWorktree(ctrl)
+	w.EXPECT().Status().Times(1).Return(git.Status{
+		"images/foo/mib.yml": &git.FileStatus{Worktree: git.Unmodified, Staging: git.Renamed, Extra: "images/bar/mib.yml"},
+		"README.md":          &git.FileStatus{Worktree: git.Modified, Staging: git.Unmodified},
+	}, nil)
+
+	g := Git{w: w, root: "/app", workingDir: "/app/images"}
+	got, err := g.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
 func TestGit_Head_Success(t *testing.T) {
 
 	ctx := context.TestContext(nil)
@@ -66,7 +99,8 @@ func TestGit_Head_Success(t *testing.T) {
 	gitManger := &Git{r: repo, w: worktree}
 	got, err := gitManger.Head()
 	assert.NoError(t, err)
-	assert.NotEqual(t, "", got)
+	head, _ := repo.Head()
+	assert.Equal(t, head.Hash().String(), got)
 }
 
 func TestGit_ResolveRevision(t *testing.T) {
@@ -555,6 +589,17 @@ func TestGit_AddWithOptions(t *testing.T) {
 	}
 }
 
+func TestGit_AddWithOptions_SuccessSubdir(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	w := mockgit.NewMockWorktree(ctrl)
+	w.EXPECT().AddWithOptions(gomock.Eq(&git.AddOptions{Path: "images/foo/README.md"})).Times(1).Return(nil)
+
+	g := Git{w: w, root: "/app", workingDir: "/app/images"}
+	err := g.AddWithOptions(&git.AddOptions{Path: "foo/README.md"})
+	assert.NoError(t, err)
+}
+
 func TestGit_GetCommitFilesChanged_SuccessLongHash(t *testing.T) {
 	ctx := context.TestContext(nil)
 	ctx.WorkingDir = "/app/repo"
@@ -576,11 +621,33 @@ func TestGit_GetCommitFilesChanged_SuccessLongHash(t *testing.T) {
 	worktree, err := repo.Worktree()
 	assert.NoError(t, err)
 	gitManger := &Git{r: repo, w: worktree}
-	got, err := gitManger.GetCommitFilesChanged(hash.String())
+	got, err := gitManger.GetCommitFilesChanged(hash.String(), false)
 	assert.NoError(t, err)
 	assert.Equal(t, want, got)
 }
 
+func TestGit_GetCommitFilesChanged_SuccessSubdir(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "images/foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "images/foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "README.md"), []byte("# Readme"), 0644)
+	hash := stageAllAndCommit(t, repo, ctx.WorkingDir, "Add image")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree, root: ctx.WorkingDir, workingDir: filepath.Join(ctx.WorkingDir, "images")}
+	got, err := gitManger.GetCommitFilesChanged(hash.String(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"../README.md", "foo/Dockerfile", "foo/mib.yml"}, got)
+
+	content, err := gitManger.CommitFileContent(&hash, "foo/mib.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, "name: foo\ntag: 0.1", content)
+}
+
 func TestGit_GetCommitFilesChanged_SuccessShortHash(t *testing.T) {
 	ctx := context.TestContext(nil)
 	ctx.WorkingDir = "/app/repo"
@@ -594,11 +661,140 @@ func TestGit_GetCommitFilesChanged_SuccessShortHash(t *testing.T) {
 	worktree, err := repo.Worktree()
 	assert.NoError(t, err)
 	gitManger := &Git{r: repo, w: worktree}
-	got, err := gitManger.GetCommitFilesChanged(hash.String()[0:20])
+	got, err := gitManger.GetCommitFilesChanged(hash.String()[0:20], false)
 	assert.NoError(t, err)
 	assert.Equal(t, want, got)
 }
 
+func TestGit_GetCommitFilesChanged_SuccessRevisions(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	first := stageAllAndCommit(t, repo, ctx.WorkingDir, "Add foo")
+	_, err := repo.CreateTag("v1", first, &git.CreateTagOptions{Message: "v1"})
+	assert.NoError(t, err)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/mib.yml"), []byte("name: bar\ntag: 0.1"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Add bar")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+	tests := []struct {
+		revision string
+		want     []string
+	}{
+		{revision: "HEAD", want: []string{"bar/mib.yml"}},
+		{revision: "main", want: []string{"bar/mib.yml"}},
+		{revision: "HEAD~1", want: []string{"foo/Dockerfile", "foo/mib.yml"}},
+		{revision: "v1", want: []string{"foo/Dockerfile", "foo/mib.yml"}},
+		{revision: first.String()[0:7], want: []string{"foo/Dockerfile", "foo/mib.yml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.revision, func(t *testing.T) {
+			got, errChanged := gitManger.GetCommitFilesChanged(tt.revision, false)
+			assert.NoError(t, errChanged)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGit_GetCommitFilesChanged_SuccessMergeCommit(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Add images")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	assert.NoError(t, err)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:12"), 0644)
+	feature := stageAllAndCommit(t, repo, ctx.WorkingDir, "Update foo")
+
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main")})
+	assert.NoError(t, err)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/Dockerfile"), []byte("FROM debian:12"), 0644)
+	main := stageAllAndCommit(t, repo, ctx.WorkingDir, "Update bar")
+
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:12"), 0644)
+	err = worktree.AddWithOptions(&git.AddOptions{All: true, Path: ctx.WorkingDir})
+	assert.NoError(t, err)
+	merge, err := worktree.Commit("Merge feature", &git.CommitOptions{Parents: []plumbing.Hash{main, feature}})
+	assert.NoError(t, err)
+
+	gitManger := &Git{r: repo, w: worktree}
+	got, err := gitManger.GetCommitFilesChanged(merge.String(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo/Dockerfile"}, got)
+
+	got, err = gitManger.GetCommitFilesChanged(merge.String(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo/Dockerfile", "bar/Dockerfile"}, got)
+}
+
+func TestGit_GetCommitFilesChanged_ErrorAmbiguousShortHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mockgit.NewMockRepository(ctrl)
+	repo.EXPECT().CommitObjects().Times(1).Return(&commitSliceIter{commits: []*object.Commit{
+		{Hash: plumbing.NewHash("abcd111111111111111111111111111111111111")},
+		{Hash: plumbing.NewHash("1234111111111111111111111111111111111111")},
+		{Hash: plumbing.NewHash("abcd222222222222222222222222222222222222")},
+	}}, nil)
+
+	gitManger := &Git{r: repo}
+	_, err := gitManger.GetCommitFilesChanged("abcd~1", false)
+	assert.EqualError(t, err, "short hash abcd is ambiguous, candidates are: abcd111111111111111111111111111111111111, abcd222222222222222222222222222222222222")
+}
+
+func TestGit_commitsWithPrefix_Success(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	hash := stageAllAndCommit(t, repo, ctx.WorkingDir, "Add foo")
+	blob := plumbing.ComputeHash(plumbing.BlobObject, []byte("FROM debian:latest"))
+
+	gitManger := &Git{r: repo}
+	got, err := gitManger.commitsWithPrefix(hash.String()[0:5])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{hash.String()}, got)
+	got, err = gitManger.commitsWithPrefix(blob.String()[0:6])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, got)
+}
+
+type commitSliceIter struct {
+	commits []*object.Commit
+}
+
+func (i *commitSliceIter) Next() (*object.Commit, error) {
+	if len(i.commits) == 0 {
+		return nil, io.EOF
+	}
+	commit := i.commits[0]
+	i.commits = i.commits[1:]
+	return commit, nil
+}
+
+func (i *commitSliceIter) ForEach(cb func(*object.Commit) error) error {
+	for _, commit := range i.commits {
+		if err := cb(commit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *commitSliceIter) Close() {}
+
 func TestGit_GetCommitFilesChanged_ErrorGetCommit(t *testing.T) {
 	ctx := context.TestContext(nil)
 	ctx.WorkingDir = "/app/repo"
@@ -611,11 +807,319 @@ func TestGit_GetCommitFilesChanged_ErrorGetCommit(t *testing.T) {
 	worktree, err := repo.Worktree()
 	assert.NoError(t, err)
 	gitManger := &Git{r: repo, w: worktree}
-	_, err = gitManger.GetCommitFilesChanged("wrong")
+	_, err = gitManger.GetCommitFilesChanged("wrong", false)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "commit not foun")
 }
 
+func TestGit_GetCommitImagesRemoved_Success(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1\nalias:\n  - name: foo\n    tag: latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/mib.yml"), []byte("name: bar\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "baz/mib.yml"), []byte("name: baz\ntag: 0.1"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Add images")
+
+	_ = ctx.FS.RemoveAll(filepath.Join(ctx.WorkingDir, "foo"))
+	_ = ctx.FS.Rename(filepath.Join(ctx.WorkingDir, "bar"), filepath.Join(ctx.WorkingDir, "images/bar"))
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "baz/mib.yml"), []byte("name: baz\ntag: 0.2"), 0644)
+	hash := stageAllAndCommit(t, repo, ctx.WorkingDir, "Remove foo and move bar")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+	got, err := gitManger.GetCommitImagesRemoved(hash.String(), false)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, types.ImagesRemoved{
+		{Names: []string{"foo:0.1", "foo:latest"}, Path: "foo"},
+		{Names: []string{"bar:0.1"}, Path: "bar", NewPath: "images/bar"},
+	}, got)
+}
+
+func TestGit_GetCommitImagesRemoved_SuccessRootCommit(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	hash := stageAllAndCommit(t, repo, ctx.WorkingDir, "Add image")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+	got, err := gitManger.GetCommitImagesRemoved(hash.String(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ImagesRemoved{}, got)
+}
+
+func TestGit_GetCommitImagesRemoved_ErrorMarshal(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: [0.1"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Add image")
+	_ = ctx.FS.Remove(filepath.Join(ctx.WorkingDir, "foo/mib.yml"))
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "README.md"), []byte("# Readme"), 0644)
+	hash := stageAllAndCommit(t, repo, ctx.WorkingDir, "Remove image")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+	_, err = gitManger.GetCommitImagesRemoved(hash.String(), false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not parse removed foo/mib.yml with error")
+}
+
+func TestGit_GetCommitImagesRemoved_ErrorGetCommit(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Add image")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+	_, err = gitManger.GetCommitImagesRemoved("wrong", false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "commit not found")
+}
+
+func TestGit_ExportCommit_Success(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/bin/run.sh"), []byte("#!/bin/sh"), 0755)
+	hash := stageAllAndCommit(t, repo, ctx.WorkingDir, "Add image")
+
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:dirty"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/untracked"), []byte("untracked"), 0644)
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+	err = gitManger.ExportCommit(hash.String(), ctx.FS, "/tmp/export")
+	assert.NoError(t, err)
+
+	content, err := afero.ReadFile(ctx.FS, "/tmp/export/foo/Dockerfile")
+	assert.NoError(t, err)
+	assert.Equal(t, "FROM debian:latest", string(content))
+	content, err = afero.ReadFile(ctx.FS, "/tmp/export/foo/mib.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, "name: foo\ntag: 0.1", string(content))
+	exist, _ := afero.Exists(ctx.FS, "/tmp/export/foo/untracked")
+	assert.False(t, exist)
+	exist, _ = afero.Exists(ctx.FS, "/tmp/export/.git")
+	assert.False(t, exist)
+}
+
+func TestGit_ExportCommit_ErrorGetCommit(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Add image")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+	err = gitManger.ExportCommit("wrong", ctx.FS, "/tmp/export")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "commit not found")
+}
+
+func TestGit_GetRangeFilesChanged_Success(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	from := stageAllAndCommit(t, repo, ctx.WorkingDir, "Add foo")
+	_, err := repo.CreateTag("v1", from, nil)
+	assert.NoError(t, err)
+
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/mib.yml"), []byte("name: bar\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Add bar")
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:12"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/Dockerfile"), []byte("FROM debian:12"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Update foo and bar")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+
+	got, err := gitManger.GetRangeFilesChanged("v1", "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bar/Dockerfile", "bar/mib.yml", "foo/Dockerfile"}, got)
+
+	got, err = gitManger.GetRangeFilesChanged("HEAD~1", "main")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bar/Dockerfile", "foo/Dockerfile"}, got)
+
+	got, err = gitManger.GetRangeFilesChanged("HEAD", "v1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, got)
+}
+
+func TestGit_GetRangeFilesChanged_ErrorResolveRevision(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Add foo")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+
+	_, err = gitManger.GetRangeFilesChanged("wrong", "HEAD")
+	assert.ErrorContains(t, err, "commit not found for revision wrong")
+	_, err = gitManger.GetRangeFilesChanged("HEAD", "wrong")
+	assert.ErrorContains(t, err, "commit not found for revision wrong")
+}
+
+func TestGit_GetBranchFilesChanged_Success(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/mib.yml"), []byte("name: bar\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Add images")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	assert.NoError(t, err)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:12"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Update foo")
+
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main")})
+	assert.NoError(t, err)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/Dockerfile"), []byte("FROM debian:12"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Update bar on main")
+
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature")})
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+	got, err := gitManger.GetBranchFilesChanged("main")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo/Dockerfile"}, got)
+}
+
+func TestGit_GetRangeFilesChanged_SuccessMergedFrom(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+	when := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	root := commitAt(t, repo, ctx.WorkingDir, "Add foo", when)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	base := commitAt(t, repo, ctx.WorkingDir, "Add foo Dockerfile", when.Add(time.Hour))
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	assert.NoError(t, err)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/mib.yml"), []byte("name: bar\ntag: 0.1"), 0644)
+	feature := commitAt(t, repo, ctx.WorkingDir, "Add bar", when.Add(2*time.Hour))
+
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main")})
+	assert.NoError(t, err)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/mib.yml"), []byte("name: bar\ntag: 0.1"), 0644)
+	_ = commitAt(t, repo, ctx.WorkingDir, "Merge feature", when.Add(3*time.Hour), base, feature)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:12"), 0644)
+	_ = commitAt(t, repo, ctx.WorkingDir, "Update foo", when.Add(4*time.Hour))
+
+	gitManger := &Git{r: repo, w: worktree}
+	got, err := gitManger.GetRangeFilesChanged("feature", "main")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bar/mib.yml", "foo/Dockerfile"}, got)
+
+	got, err = gitManger.GetRangeFilesChanged("main", "feature")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, got)
+
+	fromCommit, err := repo.CommitObject(feature)
+	assert.NoError(t, err)
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	toCommit, err := repo.CommitObject(head.Hash())
+	assert.NoError(t, err)
+	flags, _, err := paintCommits(fromCommit, toCommit)
+	assert.NoError(t, err)
+	assert.Equal(t, reachBoth|reachStale, flags[base])
+	_, walked := flags[root]
+	assert.False(t, walked)
+}
+
+func TestGit_GetBranchFilesChanged_SuccessMergedBase(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+	when := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/mib.yml"), []byte("name: bar\ntag: 0.1"), 0644)
+	_ = commitAt(t, repo, ctx.WorkingDir, "Add images", when)
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true})
+	assert.NoError(t, err)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:12"), 0644)
+	merged := commitAt(t, repo, ctx.WorkingDir, "Update foo", when.Add(time.Hour))
+
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("main")})
+	assert.NoError(t, err)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "bar/mib.yml"), []byte("name: bar\ntag: 0.2"), 0644)
+	mainHash := commitAt(t, repo, ctx.WorkingDir, "Update bar", when.Add(2*time.Hour))
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/Dockerfile"), []byte("FROM debian:12"), 0644)
+	_ = commitAt(t, repo, ctx.WorkingDir, "Merge feature", when.Add(3*time.Hour), mainHash, merged)
+
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature")})
+	assert.NoError(t, err)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.2"), 0644)
+	_ = commitAt(t, repo, ctx.WorkingDir, "Bump foo", when.Add(4*time.Hour))
+
+	gitManger := &Git{r: repo, w: worktree}
+	got, err := gitManger.GetBranchFilesChanged("main")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo/mib.yml"}, got)
+}
+
+func TestGit_GetBranchFilesChanged_ErrorResolveBase(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/app/repo"
+
+	repo := initGitRepo(t, ctx)
+	_ = afero.WriteFile(ctx.FS, filepath.Join(ctx.WorkingDir, "foo/mib.yml"), []byte("name: foo\ntag: 0.1"), 0644)
+	_ = stageAllAndCommit(t, repo, ctx.WorkingDir, "Add foo")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	gitManger := &Git{r: repo, w: worktree}
+	_, err = gitManger.GetBranchFilesChanged("develop")
+	assert.ErrorContains(t, err, "commit not found for revision develop")
+}
+
 func initGitRepo(t *testing.T, ctx *context.Context) *git.Repository {
 	memPath := filepath.Join(ctx.WorkingDir, git.GitDirName)
 	memBaseDir := afero.NewBasePathFs(ctx.FS, memPath)
@@ -650,3 +1154,220 @@ func stageAllAndCommit(t *testing.T, repo *git.Repository, path, message string)
 	assert.NoError(t, err)
 	return hash
 }
+
+// commitAt stages all files and commits them at the given date, on top of HEAD or of the given parents.
+func commitAt(t *testing.T, repo *git.Repository, path, message string, when time.Time, parents ...plumbing.Hash) plumbing.Hash {
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	err = worktree.AddWithOptions(&git.AddOptions{All: true, Path: path})
+	assert.NoError(t, err)
+	signature := &object.Signature{Name: "Dev", Email: "dev@mib.local", When: when}
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature, Committer: signature, Parents: parents})
+	assert.NoError(t, err)
+	return hash
+}
+
+// initSubmoduleRepo creates on disk a repository with the submodule shared, its pointer is committed at the first
+// commit of the submodule, the second one is returned.
+func initSubmoduleRepo(t *testing.T) (string, *git.Repository, *git.Repository, plumbing.Hash) {
+	root := t.TempDir()
+	signature := &object.Signature{Name: "Dev", Email: "dev@mib.local"}
+	repo, err := git.PlainInitWithOptions(root, &git.PlainInitOptions{InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")}})
+	assert.NoError(t, err)
+
+	subStorage := filesystem.NewStorage(osfs.New(filepath.Join(root, ".git/modules/shared")), cache.NewObjectLRUDefault())
+	subRepo, err := git.Init(subStorage, osfs.New(filepath.Join(root, "shared")))
+	assert.NoError(t, err)
+	subWorktree, err := subRepo.Worktree()
+	assert.NoError(t, err)
+	_ = os.WriteFile(filepath.Join(root, "shared/a.css"), []byte("a"), 0644)
+	_, _ = subWorktree.Add("a.css")
+	first, err := subWorktree.Commit("Add a", &git.CommitOptions{Author: signature})
+	assert.NoError(t, err)
+
+	_ = os.WriteFile(filepath.Join(root, ".gitmodules"), []byte("[submodule \"shared\"]\n\tpath = shared\n\turl = ../shared\n"), 0644)
+	cfg, err := repo.Config()
+	assert.NoError(t, err)
+	cfg.Submodules["shared"] = &config.Submodule{Name: "shared", Path: "shared", URL: "../shared"}
+	assert.NoError(t, repo.SetConfig(cfg))
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	_, _ = worktree.Add(".gitmodules")
+	setSubmodulePointer(t, repo, first)
+	_, err = worktree.Commit("Add submodule", &git.CommitOptions{Author: signature})
+	assert.NoError(t, err)
+
+	_ = os.WriteFile(filepath.Join(root, "shared/b.css"), []byte("b"), 0644)
+	_, _ = subWorktree.Add("b.css")
+	second, err := subWorktree.Commit("Add b", &git.CommitOptions{Author: signature})
+	assert.NoError(t, err)
+	return root, repo, subRepo, second
+}
+
+func setSubmodulePointer(t *testing.T, repo *git.Repository, hash plumbing.Hash) {
+	idx, err := repo.Storer.Index()
+	assert.NoError(t, err)
+	entry, errEntry := idx.Entry("shared")
+	if errEntry != nil {
+		entry = idx.Add("shared")
+	}
+	entry.Mode = filemode.Submodule
+	entry.Hash = hash
+	assert.NoError(t, repo.Storer.SetIndex(idx))
+}
+
+func TestGit_GetCommitFilesChanged_SuccessSubmodule(t *testing.T) {
+	root, repo, _, second := initSubmoduleRepo(t)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	setSubmodulePointer(t, repo, second)
+	hash, err := worktree.Commit("Update submodule", &git.CommitOptions{Author: &object.Signature{Name: "Dev", Email: "dev@mib.local"}})
+	assert.NoError(t, err)
+
+	gitManger := &Git{r: repo, w: worktree, root: root, workingDir: root}
+	got, err := gitManger.GetCommitFilesChanged(hash.String(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"shared/b.css"}, got)
+
+	got, err = gitManger.GetCommitFilesChanged("HEAD~1", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".gitmodules"}, got)
+}
+
+func TestGit_GetCommitFilesChanged_SuccessSubmoduleNotInitialized(t *testing.T) {
+	root, repo, _, second := initSubmoduleRepo(t)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	setSubmodulePointer(t, repo, second)
+	hash, err := worktree.Commit("Update submodule", &git.CommitOptions{Author: &object.Signature{Name: "Dev", Email: "dev@mib.local"}})
+	assert.NoError(t, err)
+	cfg, err := repo.Config()
+	assert.NoError(t, err)
+	delete(cfg.Submodules, "shared")
+	assert.NoError(t, repo.SetConfig(cfg))
+
+	gitManger := &Git{r: repo, w: worktree, root: root, workingDir: root}
+	got, err := gitManger.GetCommitFilesChanged(hash.String(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"shared"}, got)
+}
+
+func TestGit_ExportCommit_SuccessSubmodule(t *testing.T) {
+	root, repo, _, second := initSubmoduleRepo(t)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	first, err := repo.Head()
+	assert.NoError(t, err)
+	setSubmodulePointer(t, repo, second)
+	hash, err := worktree.Commit("Update submodule", &git.CommitOptions{Author: &object.Signature{Name: "Dev", Email: "dev@mib.local"}})
+	assert.NoError(t, err)
+
+	fs := afero.NewMemMapFs()
+	gitManger := &Git{r: repo, w: worktree, root: root, workingDir: root}
+	err = gitManger.ExportCommit(first.Hash().String(), fs, "/tmp/export-first")
+	assert.NoError(t, err)
+	content, err := afero.ReadFile(fs, "/tmp/export-first/shared/a.css")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", string(content))
+	exist, _ := afero.Exists(fs, "/tmp/export-first/shared/b.css")
+	assert.False(t, exist)
+
+	err = gitManger.ExportCommit(hash.String(), fs, "/tmp/export")
+	assert.NoError(t, err)
+	content, err = afero.ReadFile(fs, "/tmp/export/shared/b.css")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(content))
+}
+
+func TestGit_ExportCommit_ErrorSubmoduleNotInitialized(t *testing.T) {
+	root, repo, _, _ := initSubmoduleRepo(t)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	cfg, err := repo.Config()
+	assert.NoError(t, err)
+	delete(cfg.Submodules, "shared")
+	assert.NoError(t, repo.SetConfig(cfg))
+
+	gitManger := &Git{r: repo, w: worktree, root: root, workingDir: root}
+	err = gitManger.ExportCommit("HEAD", afero.NewMemMapFs(), "/tmp/export")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "fail to export submodule shared at ")
+	assert.Contains(t, err.Error(), "submodule not initialized")
+}
+
+func TestGit_GetSubmodulesFilesChanged_Success(t *testing.T) {
+	root, repo, _, _ := initSubmoduleRepo(t)
+	_ = os.WriteFile(filepath.Join(root, "shared/c.css"), []byte("c"), 0644)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	gitManger := &Git{r: repo, w: worktree, root: root, workingDir: filepath.Join(root, "images")}
+	got, err := gitManger.GetSubmodulesFilesChanged()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"../shared/b.css", "../shared/c.css"}, got)
+}
+
+func TestGetDirtyFilesChanged_SuccessStagedSubmodulePointer(t *testing.T) {
+	root, repo, _, second := initSubmoduleRepo(t)
+	setSubmodulePointer(t, repo, second)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	gitManger := &Git{r: repo, w: worktree, root: root, workingDir: root}
+	got, err := GetDirtyFilesChanged(gitManger)
+	assert.NoError(t, err)
+	assert.Contains(t, got, "shared/b.css")
+
+	_, err = worktree.Commit("Update submodule", &git.CommitOptions{Author: &object.Signature{Name: "Dev", Email: "dev@mib.local"}})
+	assert.NoError(t, err)
+	got, err = gitManger.GetSubmodulesFilesChanged()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, got)
+}
+
+func TestGetDirtyFilesChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mockgit.NewMockManager(ctrl)
+	m.EXPECT().Status().Times(1).Return(git.Status{"foo/Dockerfile": &git.FileStatus{Worktree: git.Modified, Staging: git.Unmodified}}, nil)
+	m.EXPECT().GetSubmodulesFilesChanged().Times(1).Return([]string{"shared/a.css"}, nil)
+	got, err := GetDirtyFilesChanged(m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo/Dockerfile", "shared/a.css"}, got)
+
+	m.EXPECT().Status().Times(1).Return(git.Status{}, nil)
+	m.EXPECT().GetSubmodulesFilesChanged().Times(1).Return(nil, errors.New("error"))
+	_, err = GetDirtyFilesChanged(m)
+	assert.EqualError(t, err, "error")
+}
+
+func TestCreateGit_SuccessLinkedWorktree(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInitWithOptions(root, &git.PlainInitOptions{InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")}})
+	assert.NoError(t, err)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	_ = os.MkdirAll(filepath.Join(root, "foo"), 0755)
+	_ = os.WriteFile(filepath.Join(root, "foo/Dockerfile"), []byte("FROM debian:latest"), 0644)
+	_, _ = worktree.Add("foo/Dockerfile")
+	hash, err := worktree.Commit("Add foo", &git.CommitOptions{Author: &object.Signature{Name: "Dev", Email: "dev@mib.local"}})
+	assert.NoError(t, err)
+
+	linked := t.TempDir()
+	adminDir := filepath.Join(root, ".git/worktrees/linked")
+	_ = os.MkdirAll(adminDir, 0755)
+	_ = os.WriteFile(filepath.Join(adminDir, "HEAD"), []byte(hash.String()+"\n"), 0644)
+	_ = os.WriteFile(filepath.Join(adminDir, "commondir"), []byte("../..\n"), 0644)
+	_ = os.WriteFile(filepath.Join(adminDir, "gitdir"), []byte(filepath.Join(linked, ".git")+"\n"), 0644)
+	_ = os.WriteFile(filepath.Join(linked, ".git"), []byte("gitdir: "+adminDir+"\n"), 0644)
+	_ = os.MkdirAll(filepath.Join(linked, "images"), 0755)
+
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = filepath.Join(linked, "images")
+	got, err := CreateGit(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, linked, got.RootDir())
+	files, err := got.GetCommitFilesChanged("HEAD", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"../foo/Dockerfile"}, files)
+}
@@ -0,0 +1,83 @@
+package loader
+
+import (
+	"fmt"
+	"github.com/alexandreh2ag/mib/types"
+	"path/filepath"
+	"strings"
+)
+
+func getDataFilePath(image *types.Image) string {
+	return filepath.Join(image.Path, DataFilename)
+}
+
+// checkDuplicateImages returns an error when a name or an alias is declared by two images.
+func checkDuplicateImages(images types.Images) error {
+	owners := map[string]*types.Image{}
+	for _, image := range images {
+		for _, imageName := range append([]types.ImageName{image.ImageName}, image.Alias...) {
+			// empty names are reported by the validator
+			if imageName.Name == "" {
+				continue
+			}
+			name := imageName.GetFullName()
+			owner, exist := owners[name]
+			if exist && owner != image {
+				return fmt.Errorf("image %s is declared in both %s and %s", name, getDataFilePath(owner), getDataFilePath(image))
+			}
+			owners[name] = image
+		}
+	}
+	return nil
+}
+
+// checkDependencyCycles returns an error when images depend on each other through
+// their parent or their dependencies, since they could never be built.
+func checkDependencyCycles(images types.Images) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := map[*types.Image]int{}
+	stack := types.Images{}
+	var visit func(image *types.Image) error
+	visit = func(image *types.Image) error {
+		switch states[image] {
+		case visited:
+			return nil
+		case visiting:
+			paths := []string{}
+			for i := len(stack) - 1; i >= 0; i-- {
+				paths = append([]string{getDataFilePath(stack[i])}, paths...)
+				if stack[i] == image {
+					break
+				}
+			}
+			paths = append(paths, getDataFilePath(image))
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(paths, " -> "))
+		}
+
+		states[image] = visiting
+		stack = append(stack, image)
+		requirements := image.GetLocalDependencies()
+		if image.HasLocalParent {
+			requirements = append(types.Images{image.Parent}, requirements...)
+		}
+		for _, requirement := range requirements {
+			if err := visit(requirement); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		states[image] = visited
+		return nil
+	}
+
+	for _, image := range images {
+		if err := visit(image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package loader
+
+import (
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_checkDuplicateImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		images  types.Images
+		wantErr string
+	}{
+		{
+			name:   "SuccessEmpty",
+			images: types.Images{},
+		},
+		{
+			name: "SuccessNoDuplicate",
+			images: types.Images{
+				&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", Alias: []types.ImageName{{Name: "foo", Tag: "latest"}}},
+				&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.2"}, Path: "/app/foo2"},
+			},
+		},
+		{
+			name: "FailDuplicateName",
+			images: types.Images{
+				&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo"},
+				&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/bar"},
+			},
+			wantErr: "image foo:0.1 is declared in both /app/foo/mib.yml and /app/bar/mib.yml",
+		},
+		{
+			name: "FailDuplicateAlias",
+			images: types.Images{
+				&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", Alias: []types.ImageName{{Name: "foo", Tag: "latest"}}},
+				&types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.2"}, Path: "/app/foo2", Alias: []types.ImageName{{Name: "foo", Tag: "latest"}}},
+			},
+			wantErr: "image foo:latest is declared in both /app/foo/mib.yml and /app/foo2/mib.yml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDuplicateImages(tt.images)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test_checkDependencyCycles(t *testing.T) {
+	tests := []struct {
+		name    string
+		images  func() types.Images
+		wantErr string
+	}{
+		{
+			name: "SuccessNoCycle",
+			images: func() types.Images {
+				parent := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo"}
+				tools := &types.Image{ImageName: types.ImageName{Name: "tools", Tag: "0.1"}, Path: "/app/tools"}
+				child := &types.Image{ImageName: types.ImageName{Name: "bar", Tag: "0.1"}, Path: "/app/bar", HasLocalParent: true, Parent: parent, Dependencies: types.Images{tools}}
+				return types.Images{parent, tools, child}
+			},
+		},
+		{
+			name: "FailParentCycle",
+			images: func() types.Images {
+				foo := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", HasLocalParent: true}
+				bar := &types.Image{ImageName: types.ImageName{Name: "bar", Tag: "0.1"}, Path: "/app/bar", HasLocalParent: true, Parent: foo}
+				foo.Parent = bar
+				return types.Images{foo, bar}
+			},
+			wantErr: "dependency cycle detected: /app/foo/mib.yml -> /app/bar/mib.yml -> /app/foo/mib.yml",
+		},
+		{
+			name: "FailSelfParent",
+			images: func() types.Images {
+				foo := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", HasLocalParent: true}
+				foo.Parent = foo
+				return types.Images{foo}
+			},
+			wantErr: "dependency cycle detected: /app/foo/mib.yml -> /app/foo/mib.yml",
+		},
+		{
+			name: "FailDependencyCycle",
+			images: func() types.Images {
+				base := &types.Image{ImageName: types.ImageName{Name: "base", Tag: "0.1"}, Path: "/app/base"}
+				tools := &types.Image{ImageName: types.ImageName{Name: "tools", Tag: "0.1"}, Path: "/app/tools", HasLocalParent: true, Parent: base}
+				foo := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", Dependencies: types.Images{tools}}
+				base.Dependencies = types.Images{foo}
+				return types.Images{base, tools, foo}
+			},
+			wantErr: "dependency cycle detected: /app/base/mib.yml -> /app/foo/mib.yml -> /app/tools/mib.yml -> /app/base/mib.yml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDependencyCycles(tt.images())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package loader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/afero"
+	"hash"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ComputeContentHash returns the content hash of the image and keeps it in image.ContentHash. It covers the
+// Dockerfile, the files of the build context not ignored by .dockerignore, the build args, the target, the platforms and the hash
+// of local parent and dependencies, or the name of remote ones, so it only changes when the built image would.
+func ComputeContentHash(ctx *context.Context, image *types.Image) (string, error) {
+	if image.ContentHash != "" {
+		return image.ContentHash, nil
+	}
+	afs := &afero.Afero{Fs: ctx.FS}
+	h := sha256.New()
+
+	dockerfileContent, err := afs.ReadFile(image.GetDockerfilePath())
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("could not read dockerFile of image %s", image.GetFullName())
+	}
+	writeHashField(h, "dockerfile", image.GetDockerfile())
+	writeHashField(h, "dockerfile-content", string(dockerfileContent))
+
+	errContext := hashContextFiles(ctx, h, image)
+	if errContext != nil {
+		return "", fmt.Errorf("could not hash context of image %s with error: %v", image.GetFullName(), errContext)
+	}
+
+	buildArgs := image.GetBuildArgsValues()
+	buildArgKeys := []string{}
+	for key := range buildArgs {
+		buildArgKeys = append(buildArgKeys, key)
+	}
+	slices.Sort(buildArgKeys)
+	for _, key := range buildArgKeys {
+		writeHashField(h, "build-arg", key+"="+buildArgs[key])
+	}
+	writeHashField(h, "target", image.Target)
+	for _, platform := range image.Platforms {
+		writeHashField(h, "platform", platform)
+	}
+
+	if image.Parent != nil {
+		parent, errParent := getImageHashReference(ctx, image.Parent, image.HasLocalParent)
+		if errParent != nil {
+			return "", errParent
+		}
+		writeHashField(h, "parent", parent)
+	}
+	for _, dependency := range image.Dependencies {
+		dependencyRef, errDependency := getImageHashReference(ctx, dependency, dependency.IsLocal())
+		if errDependency != nil {
+			return "", errDependency
+		}
+		writeHashField(h, "dependency", dependencyRef)
+	}
+
+	image.ContentHash = "sha256:" + hex.EncodeToString(h.Sum(nil))
+	return image.ContentHash, nil
+}
+
+// getImageHashReference returns the content hash of a local image, or the name (with digest when pinned) of a remote one.
+func getImageHashReference(ctx *context.Context, image *types.Image, isLocal bool) (string, error) {
+	if !isLocal {
+		return image.GetFullName(), nil
+	}
+	return ComputeContentHash(ctx, image)
+}
+
+// hashContextFiles hashes the path, content and executable bit of files of the build context, in lexical order,
+// without files ignored by .dockerignore, so it covers every file sent to the builder.
+func hashContextFiles(ctx *context.Context, h hash.Hash, image *types.Image) error {
+	afs := &afero.Afero{Fs: ctx.FS}
+	contextPath := image.GetContextPath()
+	if exists, _ := afs.DirExists(contextPath); !exists {
+		return nil
+	}
+	return afs.Walk(contextPath, func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || image.IsDockerIgnored(fp) {
+			return nil
+		}
+		relativePath, _ := filepath.Rel(contextPath, fp)
+		writeHashField(h, "file", filepath.ToSlash(relativePath))
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if reader, ok := ctx.FS.(afero.LinkReader); ok {
+				target, errLink := reader.ReadlinkIfPossible(fp)
+				if errLink != nil {
+					return errLink
+				}
+				writeHashField(h, "link", target)
+			}
+			return nil
+		}
+		content, errRead := afs.ReadFile(fp)
+		if errRead != nil {
+			return errRead
+		}
+		fileHash := sha256.Sum256(content)
+		writeHashField(h, "content", hex.EncodeToString(fileHash[:]))
+		writeHashField(h, "executable", fmt.Sprintf("%t", fi.Mode()&0111 != 0))
+		return nil
+	})
+}
+
+// writeHashField writes a length prefixed field, so that values can not be shifted from a field to the next one.
+func writeHashField(h hash.Hash, key string, value string) {
+	_, _ = fmt.Fprintf(h, "%s %d %s\n", key, len(value), value)
+}
@@ -0,0 +1,224 @@
+package loader
+
+import (
+	"errors"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/types"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+type errorOpenFs struct {
+	afero.Fs
+	path string
+}
+
+func (fs errorOpenFs) Open(name string) (afero.File, error) {
+	if name == fs.path {
+		return nil, errors.New("error")
+	}
+	return fs.Fs.Open(name)
+}
+
+func createHashImages(ctx *context.Context) (*types.Image, *types.Image) {
+	_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM alpine:3.19"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/bin/run.sh", []byte("#!/bin/sh"), 0755)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/README.md", []byte("# foo"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/.git/HEAD", []byte("ref: refs/heads/main"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/bar/Dockerfile", []byte("FROM foo:0.1"), 0644)
+	parent := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", RelativeDir: "foo", Parent: &types.Image{ImageName: types.ImageName{Name: "alpine", Tag: "3.19"}}}
+	child := &types.Image{ImageName: types.ImageName{Name: "bar", Tag: "0.1"}, Path: "/app/bar", RelativeDir: "bar", Parent: parent, HasLocalParent: true}
+	parent.Children = types.Images{child}
+	return parent, child
+}
+
+func TestComputeContentHash(t *testing.T) {
+	baseCtx := context.TestContext(nil)
+	baseParent, baseChild := createHashImages(baseCtx)
+	wantParent, errParent := ComputeContentHash(baseCtx, baseParent)
+	assert.NoError(t, errParent)
+	wantChild, errChild := ComputeContentHash(baseCtx, baseChild)
+	assert.NoError(t, errChild)
+	assert.Regexp(t, regexp.MustCompile(`^sha256:[0-9a-f]{64}$`), wantParent)
+	assert.NotEqual(t, wantParent, wantChild)
+
+	tests := []struct {
+		name          string
+		preFn         func(ctx *context.Context, parent *types.Image, child *types.Image)
+		parentChanged bool
+	}{
+		{
+			name:  "SameContent",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {},
+		},
+		{
+			name: "FileChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				_ = afero.WriteFile(ctx.FS, "/app/foo/bin/run.sh", []byte("#!/bin/bash"), 0755)
+			},
+			parentChanged: true,
+		},
+		{
+			name: "FileAdded",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				_ = afero.WriteFile(ctx.FS, "/app/foo/bin/other.sh", []byte(""), 0644)
+			},
+			parentChanged: true,
+		},
+		{
+			name: "FileModeChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				_ = ctx.FS.Chmod("/app/foo/bin/run.sh", 0644)
+			},
+			parentChanged: true,
+		},
+		{
+			name: "DockerfileChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				_ = afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM alpine:3.19\nRUN true"), 0644)
+			},
+			parentChanged: true,
+		},
+		{
+			name: "BuildArgsChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				parent.BuildArgs = map[string]string{"VERSION": "2.0"}
+			},
+			parentChanged: true,
+		},
+		{
+			name: "TargetChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				parent.Target = "runtime"
+			},
+			parentChanged: true,
+		},
+		{
+			name: "RemoteParentDigestChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				parent.Parent.Digest = "sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b"
+			},
+			parentChanged: true,
+		},
+		{
+			name: "DependencyChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				parent.Dependencies = types.Images{&types.Image{ImageName: types.ImageName{Name: "golang", Tag: "1.22"}}}
+			},
+			parentChanged: true,
+		},
+		{
+			name: "ExcludedExtensionChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				_ = afero.WriteFile(ctx.FS, "/app/foo/README.md", []byte("# foo image"), 0644)
+			},
+			parentChanged: true,
+		},
+		{
+			name: "TextFileChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				_ = afero.WriteFile(ctx.FS, "/app/foo/notes.txt", []byte("copied in the image"), 0644)
+			},
+			parentChanged: true,
+		},
+		{
+			name: "IgnoredByImageChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				parent.Ignore = []string{"bin"}
+				_ = afero.WriteFile(ctx.FS, "/app/foo/bin/run.sh", []byte("#!/bin/bash"), 0755)
+			},
+			parentChanged: true,
+		},
+		{
+			name: "GitDirChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				_ = afero.WriteFile(ctx.FS, "/app/foo/.git/HEAD", []byte("ref: refs/heads/other"), 0644)
+			},
+			parentChanged: true,
+		},
+		{
+			name: "FileOutsideContextChanged",
+			preFn: func(ctx *context.Context, parent *types.Image, child *types.Image) {
+				_ = afero.WriteFile(ctx.FS, "/app/other/Dockerfile", []byte("FROM debian"), 0644)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			parent, child := createHashImages(ctx)
+			tt.preFn(ctx, parent, child)
+			gotParent, err := ComputeContentHash(ctx, parent)
+			assert.NoError(t, err)
+			gotChild, err := ComputeContentHash(ctx, child)
+			assert.NoError(t, err)
+			assert.Equal(t, gotParent, parent.ContentHash)
+			if tt.parentChanged {
+				assert.NotEqual(t, wantParent, gotParent)
+				assert.NotEqual(t, wantChild, gotChild)
+				return
+			}
+			assert.Equal(t, wantParent, gotParent)
+			assert.Equal(t, wantChild, gotChild)
+		})
+	}
+}
+
+func TestComputeContentHash_SuccessDockerIgnore(t *testing.T) {
+	ctx := context.TestContext(nil)
+	parent, _ := createHashImages(ctx)
+	parent.DockerIgnore = []string{"bin", ".git", "*.txt"}
+	want, _ := ComputeContentHash(ctx, parent)
+
+	_ = afero.WriteFile(ctx.FS, "/app/foo/bin/run.sh", []byte("#!/bin/bash"), 0755)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/.git/HEAD", []byte("ref: refs/heads/other"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/app/foo/notes.txt", []byte("not sent to the builder"), 0644)
+	parent.ContentHash = ""
+	got, err := ComputeContentHash(ctx, parent)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestComputeContentHash_SuccessAlreadyComputed(t *testing.T) {
+	ctx := context.TestContext(nil)
+	image := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", ContentHash: "sha256:foo"}
+	got, err := ComputeContentHash(ctx, image)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:foo", got)
+}
+
+func TestComputeContentHash_SuccessMissingFiles(t *testing.T) {
+	ctx := context.TestContext(nil)
+	image := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo"}
+	got, err := ComputeContentHash(ctx, image)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, got)
+}
+
+func TestComputeContentHash_ErrorReadDockerfile(t *testing.T) {
+	ctx := context.TestContext(nil)
+	parent, _ := createHashImages(ctx)
+	ctx.FS = errorOpenFs{Fs: ctx.FS, path: "/app/foo/Dockerfile"}
+	_, err := ComputeContentHash(ctx, parent)
+	assert.EqualError(t, err, "could not read dockerFile of image foo:0.1")
+}
+
+func TestComputeContentHash_ErrorReadContext(t *testing.T) {
+	ctx := context.TestContext(nil)
+	parent, _ := createHashImages(ctx)
+	ctx.FS = errorOpenFs{Fs: ctx.FS, path: "/app/foo/bin/run.sh"}
+	_, err := ComputeContentHash(ctx, parent)
+	assert.EqualError(t, err, "could not hash context of image foo:0.1 with error: error")
+}
+
+func TestComputeContentHash_ErrorParent(t *testing.T) {
+	ctx := context.TestContext(nil)
+	_, child := createHashImages(ctx)
+	ctx.FS = errorOpenFs{Fs: ctx.FS, path: "/app/foo/Dockerfile"}
+	_, err := ComputeContentHash(ctx, child)
+	assert.EqualError(t, err, "could not read dockerFile of image foo:0.1")
+	assert.Empty(t, child.ContentHash)
+}
@@ -1,14 +1,19 @@
 package loader
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/dockerfile"
 	"github.com/alexandreh2ag/mib/types"
 	validatorMIB "github.com/alexandreh2ag/mib/validator"
+	"github.com/distribution/reference"
 	"github.com/go-playground/validator/v10"
+	"github.com/moby/patternmatcher/ignorefile"
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
@@ -44,7 +49,16 @@ func LoadImages(ctx *context.Context) (types.Images, error) {
 		}
 		return nil
 	})
+	if err := checkDuplicateImages(images); err != nil {
+		return nil, err
+	}
+	if err := resolveInheritedParents(ctx, images); err != nil {
+		return nil, err
+	}
 	imagesOrdered := orderDependencyImages(images)
+	if err := checkDependencyCycles(images); err != nil {
+		return nil, err
+	}
 	validate := validatorMIB.New()
 	err := validate.Var(imagesOrdered, "dive")
 	if err != nil {
@@ -66,9 +80,17 @@ func orderDependencyImages(imagesToSort types.Images) types.Images {
 	imagesSorted := types.Images{}
 	for _, mainImageData := range imagesToSort {
 		for _, imageData := range imagesToSort {
+			for i, dependency := range imageData.Dependencies {
+				if mainImageData.HasName(dependency.ImageName) {
+					imageData.Dependencies[i] = mainImageData
+					mainImageData.Dependents = append(mainImageData.Dependents, imageData)
+				}
+			}
 			if imageData.Parent != nil {
-				isParent := mainImageData.Name == imageData.Parent.Name && mainImageData.Tag == imageData.Parent.Tag
-				if isParent {
+				if mainImageData.HasName(imageData.Parent.ImageName) {
+					if mainImageData.GetFullName() != (types.ImageName{Name: imageData.Parent.Name, Tag: imageData.Parent.Tag}).GetFullName() {
+						imageData.ParentAlias = imageData.Parent.GetFullName()
+					}
 					imageData.HasParentToBuild = true
 					imageData.HasLocalParent = true
 					imageData.Parent = mainImageData
@@ -96,6 +118,7 @@ func LoadImage(ctx *context.Context, path string) (types.Image, error) {
 	image.Path = filepath.Dir(path)
 	relativePath, _ := filepath.Rel(ctx.WorkingDir, image.Path)
 	image.RelativeDir = relativePath
+	image.RepoDir = findRepoDir(ctx)
 	content, err := afs.ReadFile(path)
 	if err != nil {
 		return image, fmt.Errorf("could not load file %s", path)
@@ -106,7 +129,12 @@ func LoadImage(ctx *context.Context, path string) (types.Image, error) {
 		return image, fmt.Errorf("could not parse %s with error : %s", path, err)
 	}
 
-	err = findParentImage(ctx, &image)
+	err = findParentImage(ctx, &image, types.ExpandEnvValues(image.BuildArgs))
+	if err != nil {
+		return image, err
+	}
+
+	err = loadDockerIgnore(ctx, &image)
 	if err != nil {
 		return image, err
 	}
@@ -116,33 +144,200 @@ func LoadImage(ctx *context.Context, path string) (types.Image, error) {
 	return image, nil
 }
 
-func findParentImage(ctx *context.Context, image *types.Image) error {
+// findRepoDir returns the root dir of the git repository containing the working dir, the first parent dir with a
+// .git, or an empty string when there is none.
+func findRepoDir(ctx *context.Context) string {
+	if ctx.RepoDir != "" {
+		return ctx.RepoDir
+	}
+	dir := filepath.Clean(ctx.WorkingDir)
+	for {
+		if exists, _ := afero.Exists(ctx.FS, filepath.Join(dir, ".git")); exists {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// findParentImage sets the parent and dependencies of the image from its Dockerfile, with ARG set to buildArgs.
+func findParentImage(ctx *context.Context, image *types.Image, buildArgs map[string]string) error {
 	afs := &afero.Afero{Fs: ctx.FS}
-	parentImage := types.Image{}
-	dockerFileContent, err := afs.ReadFile(filepath.Join(image.Path, "/Dockerfile"))
+	dockerFileContent, err := afs.ReadFile(image.GetDockerfilePath())
 
 	if err != nil {
 		return fmt.Errorf("could not read dockerFile of image %s", image.GetFullName())
 	}
 
-	rgx := regexp.MustCompile(`FROM\s*(.*):(.*)`)
-	regexResult := rgx.FindStringSubmatch(string(dockerFileContent))
+	df, err := dockerfile.Parse(string(dockerFileContent), buildArgs)
+	if err != nil {
+		return fmt.Errorf("dockerFile of %s is not valid: %v", image.GetFullName(), err)
+	}
 
-	if len(regexResult) > 3 || len(regexResult) == 0 {
-		return fmt.Errorf("dockerFile of %s contains zero or multiple FROM", image.GetFullName())
+	stage := df.FinalStage()
+	if image.Target != "" {
+		stage = df.GetStage(image.Target)
+		if stage == nil {
+			return fmt.Errorf("dockerFile of %s has no stage named %s", image.GetFullName(), image.Target)
+		}
+	}
+
+	parentRef := df.BaseImage(stage)
+	var parentImage *types.Image
+	var dependencies types.Images
+	for _, ref := range df.ExternalImages() {
+		dependency, errRef := newImageFromReference(ref)
+		if errRef != nil {
+			return fmt.Errorf("dockerFile of %s: %v", image.GetFullName(), errRef)
+		}
+		if ref == parentRef {
+			parentImage = dependency
+		} else {
+			dependencies = append(dependencies, dependency)
+		}
 	}
 
-	parentImage.Name = regexResult[1]
-	parentImage.Tag = regexResult[2]
-	image.Parent = &parentImage
+	image.Parent = parentImage
+	image.Dependencies = dependencies
 
 	return nil
 }
 
+// resolveInheritedParents resolves again the parent of images with the build args inherited from their local
+// parents, which docker build receives too (see Image.GetAllBuildArgs), until parents no longer change.
+func resolveInheritedParents(ctx *context.Context, images types.Images) error {
+	argsUsed := map[*types.Image]map[string]string{}
+	for _, image := range images {
+		argsUsed[image] = image.BuildArgs
+	}
+	for round := 0; round <= len(images); round++ {
+		changed := false
+		for _, image := range images {
+			buildArgs := getInheritedBuildArgs(images, image)
+			if maps.Equal(buildArgs, argsUsed[image]) {
+				continue
+			}
+			argsUsed[image] = buildArgs
+			changed = true
+			err := findParentImage(ctx, image, types.ExpandEnvValues(buildArgs))
+			if err != nil {
+				return err
+			}
+		}
+		if !changed {
+			return nil
+		}
+	}
+	for _, image := range images {
+		if !maps.Equal(getInheritedBuildArgs(images, image), argsUsed[image]) {
+			return fmt.Errorf("parent of %s changes with the build args inherited from it", image.GetFullName())
+		}
+	}
+	return nil
+}
+
+// getInheritedBuildArgs returns the build args of the image over those of its local parents, found by name
+// since images are not linked yet.
+func getInheritedBuildArgs(images types.Images, image *types.Image) map[string]string {
+	chain := types.Images{image}
+	for current := image; current.Parent != nil; {
+		var parent *types.Image
+		for _, candidate := range images {
+			if candidate.HasName(current.Parent.ImageName) {
+				parent = candidate
+				break
+			}
+		}
+		if parent == nil || slices.Contains(chain, parent) {
+			break
+		}
+		chain = append(chain, parent)
+		current = parent
+	}
+	buildArgs := map[string]string{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		for key, value := range chain[i].BuildArgs {
+			buildArgs[key] = value
+		}
+	}
+	return buildArgs
+}
+
+// loadDockerIgnore reads the ignore file used by docker build, <Dockerfile>.dockerignore
+// takes precedence over the .dockerignore at the root of the context.
+func loadDockerIgnore(ctx *context.Context, image *types.Image) error {
+	afs := &afero.Afero{Fs: ctx.FS}
+	ignoreFiles := []string{
+		image.GetDockerfilePath() + ".dockerignore",
+		filepath.Join(image.GetContextPath(), ".dockerignore"),
+	}
+	for _, ignoreFile := range ignoreFiles {
+		content, err := afs.ReadFile(ignoreFile)
+		if err != nil {
+			continue
+		}
+		patterns, err := ignorefile.ReadAll(bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("could not parse %s with error : %s", ignoreFile, err)
+		}
+		// like docker, the Dockerfile and the ignore file are always part of the context, and so is mib.yml
+		for _, keptFile := range []string{image.GetDockerfilePath(), ignoreFile, filepath.Join(image.Path, DataFilename)} {
+			relativePath, errRel := filepath.Rel(image.GetContextPath(), keptFile)
+			if errRel == nil && !strings.HasPrefix(relativePath, "..") {
+				patterns = append(patterns, "!"+relativePath)
+			}
+		}
+		image.DockerIgnore = patterns
+		return nil
+	}
+	return nil
+}
+
+func newImageFromReference(ref string) (*types.Image, error) {
+	parsed, err := reference.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("image %s is not valid: %v", ref, err)
+	}
+	named, ok := parsed.(reference.Named)
+	if !ok {
+		return nil, fmt.Errorf("image %s has no name", ref)
+	}
+
+	imageName := types.ImageName{Name: named.Name()}
+	tagged, isTagged := named.(reference.Tagged)
+	if isTagged {
+		imageName.Tag = tagged.Tag()
+	}
+	if digested, isDigested := named.(reference.Digested); isDigested {
+		imageName.Digest = digested.Digest().String()
+	} else if !isTagged {
+		imageName.Tag = "latest"
+	}
+
+	return &types.Image{ImageName: imageName}, nil
+}
+
+// RemoveExtExcludePath returns the absolute path of updated files, without those excluded by extensionExclude.
+// It is a comma separated list of extensions (like .md) or gitignore-style patterns relative to the working dir.
 func RemoveExtExcludePath(workingDir string, extensionExclude string, filesUpdated []string) []string {
+	extensions := []string{}
+	patterns := []string{}
+	for _, exclude := range strings.Split(extensionExclude, ",") {
+		exclude = strings.TrimSpace(exclude)
+		switch {
+		case exclude == "":
+		case strings.HasPrefix(exclude, ".") && !strings.ContainsAny(exclude, "/*?["):
+			extensions = append(extensions, exclude)
+		default:
+			patterns = append(patterns, exclude)
+		}
+	}
 	pathsChanged := []string{}
 	for _, file := range filesUpdated {
-		if extensionExclude == "" || !slices.Contains(strings.Split(extensionExclude, ","), filepath.Ext(file)) {
+		if !slices.Contains(extensions, filepath.Ext(file)) && !types.MatchPatterns(patterns, "", file) {
 			fileChangedAbsolute, _ := filepath.Abs(filepath.Join(workingDir, file))
 			pathsChanged = append(pathsChanged, fileChangedAbsolute)
 		}
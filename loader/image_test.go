@@ -94,6 +94,95 @@ func Test_findParentImage(t *testing.T) {
 			},
 			want: &types.Image{Path: "/app/test", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}},
 		},
+		{
+			name: "CheckOKWithMultiStage",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM --platform=$BUILDPLATFORM golang:1.22 AS builder\nFROM debian:latest AS base\nFROM base\nCOPY --from=builder /app /app"), 0644)
+			},
+			want: &types.Image{
+				Path:         "/app/test",
+				Parent:       &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}},
+				Dependencies: types.Images{&types.Image{ImageName: types.ImageName{Name: "golang", Tag: "1.22"}}},
+			},
+		},
+		{
+			name: "CheckOKWithCustomDockerfileAndTarget",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test", Dockerfile: "docker/prod.Dockerfile", Target: "Runtime"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM alpine:latest"), 0644)
+				afero.WriteFile(ctx.FS, "/app/test/docker/prod.Dockerfile", []byte("FROM golang:1.22 AS builder\nFROM debian:latest AS runtime\nCOPY --from=builder /app /app\nFROM runtime AS debug\nFROM busybox:latest"), 0644)
+			},
+			want: &types.Image{
+				Path:         "/app/test",
+				Dockerfile:   "docker/prod.Dockerfile",
+				Target:       "Runtime",
+				Parent:       &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}},
+				Dependencies: types.Images{&types.Image{ImageName: types.ImageName{Name: "golang", Tag: "1.22"}}, &types.Image{ImageName: types.ImageName{Name: "busybox", Tag: "latest"}}},
+			},
+		},
+		{
+			name: "CheckFailUnknownTarget",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test", Target: "runtime"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM debian:latest"), 0644)
+			},
+			want:    &types.Image{Path: "/app/test", Target: "runtime"},
+			wantErr: true,
+		},
+		{
+			name: "CheckFailMissingCustomDockerfile",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test", Dockerfile: "prod.Dockerfile"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM debian:latest"), 0644)
+			},
+			want:    &types.Image{Path: "/app/test", Dockerfile: "prod.Dockerfile"},
+			wantErr: true,
+		},
+		{
+			name: "CheckOKWithCopyFromImage",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM debian:latest\nCOPY --from=registry/tools:1.2 /bin/tool /bin/tool\nRUN --mount=type=bind,from=debian:latest,target=/mnt true"), 0644)
+			},
+			want: &types.Image{
+				Path:         "/app/test",
+				Parent:       &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}},
+				Dependencies: types.Images{&types.Image{ImageName: types.ImageName{Name: "registry/tools", Tag: "1.2"}}},
+			},
+		},
+		{
+			name: "CheckOKWithFinalStageFromScratch",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM registry.example.com:5000/golang:1.22 AS builder\nFROM scratch"), 0644)
+			},
+			want: &types.Image{
+				Path:         "/app/test",
+				Dependencies: types.Images{&types.Image{ImageName: types.ImageName{Name: "registry.example.com:5000/golang", Tag: "1.22"}}},
+			},
+		},
+		{
+			name: "CheckOKWithArgs",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test", BuildArgs: map[string]string{"BASE_TAG": "0.2"}}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("ARG BASE_NAME=registry.example.com/base\nARG BASE_TAG=0.1\nFROM ${BASE_NAME}:${BASE_TAG}"), 0644)
+			},
+			want: &types.Image{
+				Path:      "/app/test",
+				BuildArgs: map[string]string{"BASE_TAG": "0.2"},
+				Parent:    &types.Image{ImageName: types.ImageName{Name: "registry.example.com/base", Tag: "0.2"}},
+			},
+		},
+		{
+			name: "CheckFailedWhenNoFrom",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("RUN echo foo"), 0644)
+			},
+			want:    &types.Image{Path: "/app/test"},
+			wantErr: true,
+		},
 		{
 			name:    "CheckFailedWhenDockerfileNotExist",
 			args:    args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test"}},
@@ -102,11 +191,48 @@ func Test_findParentImage(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "CheckFailedWhenParentNameIsNotParsable",
+			name: "CheckOKWithImplicitLatest",
 			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test"}},
 			preRun: func(ctx *context.Context) {
 				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM debian"), 0644)
 			},
+			want: &types.Image{Path: "/app/test", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}},
+		},
+		{
+			name: "CheckOKWithDigest",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM alpine:3.19@sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b\nCOPY --from=golang@sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b /go /go"), 0644)
+			},
+			want: &types.Image{
+				Path:         "/app/test",
+				Parent:       &types.Image{ImageName: types.ImageName{Name: "alpine", Tag: "3.19", Digest: "sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b"}},
+				Dependencies: types.Images{&types.Image{ImageName: types.ImageName{Name: "golang", Digest: "sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b"}}},
+			},
+		},
+		{
+			name: "CheckOKWithRegistryPort",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM localhost:5000/foo"), 0644)
+			},
+			want: &types.Image{Path: "/app/test", Parent: &types.Image{ImageName: types.ImageName{Name: "localhost:5000/foo", Tag: "latest"}}},
+		},
+		{
+			name: "CheckFailedWhenParentNameIsNotParsable",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM Debian:latest"), 0644)
+			},
+			want:    &types.Image{Path: "/app/test"},
+			wantErr: true,
+		},
+		{
+			name: "CheckFailedWhenArgIsNotResolved",
+			args: args{ctx: context.TestContext(nil), image: &types.Image{Path: "/app/test"}},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM debian:${UNKNOWN}"), 0644)
+			},
 			want:    &types.Image{Path: "/app/test"},
 			wantErr: true,
 		},
@@ -114,7 +240,7 @@ func Test_findParentImage(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.preRun(tt.args.ctx)
-			if err := findParentImage(tt.args.ctx, tt.args.image); (err != nil) != tt.wantErr {
+			if err := findParentImage(tt.args.ctx, tt.args.image, types.ExpandEnvValues(tt.args.image.BuildArgs)); (err != nil) != tt.wantErr {
 				t.Errorf("findParentImage() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
@@ -198,6 +324,134 @@ func TestLoadImages(t *testing.T) {
 			}(),
 			wantErr: assert.NoError,
 		},
+		{
+			name: "SuccessWithDependency",
+			args: args{ctx: context.TestContext(nil)},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/tools/mib.yml", []byte("name: tools\ntag: 0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/tools/Dockerfile", []byte("FROM debian:latest"), 0644)
+				afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM debian:latest\nCOPY --from=tools:0.1 /bin/tool /bin/tool"), 0644)
+			},
+			want: func() types.Images {
+				tools := &types.Image{ImageName: types.ImageName{Name: "tools", Tag: "0.1"}, Path: "/app/tools", RelativeDir: "tools", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}}
+				foo := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", RelativeDir: "foo", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}, Dependencies: types.Images{tools}}
+				tools.Dependents = types.Images{foo}
+				return types.Images{foo, tools}
+			}(),
+			wantErr: assert.NoError,
+		},
+		{
+			name: "SuccessWithChildUsingArgs",
+			args: args{ctx: context.TestContext(nil)},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/mib.yml", []byte("name: test\ntag: 0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM debian:latest"), 0644)
+				afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1\nbuildArgs:\n  BASE_TAG: '0.1'"), 0644)
+				afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("ARG BASE_TAG=0.0\nFROM test:${BASE_TAG}"), 0644)
+			},
+			want: func() types.Images {
+				parent := &types.Image{ImageName: types.ImageName{Name: "test", Tag: "0.1"}, Path: "/app/test", RelativeDir: "test", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}}
+				child := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, BuildArgs: map[string]string{"BASE_TAG": "0.1"}, HasLocalParent: true, HasParentToBuild: true, Path: "/app/foo", RelativeDir: "foo", Parent: parent}
+				parent.Children = types.Images{child}
+				return types.Images{
+					parent,
+				}
+			}(),
+			wantErr: assert.NoError,
+		},
+		{
+			name: "SuccessWithChildUsingInheritedArgs",
+			args: args{ctx: context.TestContext(nil)},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/common/mib.yml", []byte("name: common\ntag: 0.1\nbuildArgs:\n  PHP_VERSION: '8.3'"), 0644)
+				afero.WriteFile(ctx.FS, "/app/common/Dockerfile", []byte("FROM debian:latest"), 0644)
+				afero.WriteFile(ctx.FS, "/app/php82/mib.yml", []byte("name: php\ntag: '8.2'"), 0644)
+				afero.WriteFile(ctx.FS, "/app/php82/Dockerfile", []byte("FROM common:0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/php83/mib.yml", []byte("name: php\ntag: '8.3'"), 0644)
+				afero.WriteFile(ctx.FS, "/app/php83/Dockerfile", []byte("FROM common:0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/z-app/mib.yml", []byte("name: app\ntag: 0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/z-app/Dockerfile", []byte("ARG PHP_VERSION=8.2\nFROM php:${PHP_VERSION}"), 0644)
+			},
+			want: func() types.Images {
+				common := &types.Image{ImageName: types.ImageName{Name: "common", Tag: "0.1"}, BuildArgs: map[string]string{"PHP_VERSION": "8.3"}, Path: "/app/common", RelativeDir: "common", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}}
+				php82 := &types.Image{ImageName: types.ImageName{Name: "php", Tag: "8.2"}, HasLocalParent: true, HasParentToBuild: true, Path: "/app/php82", RelativeDir: "php82", Parent: common}
+				php83 := &types.Image{ImageName: types.ImageName{Name: "php", Tag: "8.3"}, HasLocalParent: true, HasParentToBuild: true, Path: "/app/php83", RelativeDir: "php83", Parent: common}
+				app := &types.Image{ImageName: types.ImageName{Name: "app", Tag: "0.1"}, HasLocalParent: true, HasParentToBuild: true, Path: "/app/z-app", RelativeDir: "z-app", Parent: php83}
+				common.Children = types.Images{php82, php83}
+				php83.Children = types.Images{app}
+				return types.Images{common}
+			}(),
+			wantErr: assert.NoError,
+		},
+		{
+			name: "SuccessWithChildUsingInheritedArgsDropDependency",
+			args: args{ctx: context.TestContext(nil)},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/common/mib.yml", []byte("name: common\ntag: 0.1\nbuildArgs:\n  PHP_VERSION: '8.3'\n  ASSETS: scratch"), 0644)
+				afero.WriteFile(ctx.FS, "/app/common/Dockerfile", []byte("FROM debian:latest"), 0644)
+				afero.WriteFile(ctx.FS, "/app/php82/mib.yml", []byte("name: php\ntag: '8.2'"), 0644)
+				afero.WriteFile(ctx.FS, "/app/php82/Dockerfile", []byte("FROM common:0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/php83/mib.yml", []byte("name: php\ntag: '8.3'"), 0644)
+				afero.WriteFile(ctx.FS, "/app/php83/Dockerfile", []byte("FROM common:0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/z-app/mib.yml", []byte("name: app\ntag: 0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/z-app/Dockerfile", []byte("ARG PHP_VERSION=8.2\nARG ASSETS=assets:0.1\nFROM ${ASSETS} AS assets\nFROM php:${PHP_VERSION}\nCOPY --from=assets /srv /srv"), 0644)
+			},
+			want: func() types.Images {
+				common := &types.Image{ImageName: types.ImageName{Name: "common", Tag: "0.1"}, BuildArgs: map[string]string{"PHP_VERSION": "8.3", "ASSETS": "scratch"}, Path: "/app/common", RelativeDir: "common", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}}
+				php82 := &types.Image{ImageName: types.ImageName{Name: "php", Tag: "8.2"}, HasLocalParent: true, HasParentToBuild: true, Path: "/app/php82", RelativeDir: "php82", Parent: common}
+				php83 := &types.Image{ImageName: types.ImageName{Name: "php", Tag: "8.3"}, HasLocalParent: true, HasParentToBuild: true, Path: "/app/php83", RelativeDir: "php83", Parent: common}
+				app := &types.Image{ImageName: types.ImageName{Name: "app", Tag: "0.1"}, HasLocalParent: true, HasParentToBuild: true, Path: "/app/z-app", RelativeDir: "z-app", Parent: php83}
+				common.Children = types.Images{php82, php83}
+				php83.Children = types.Images{app}
+				return types.Images{common}
+			}(),
+			wantErr: assert.NoError,
+		},
+		{
+			name: "FailInheritedArgsChangeParent",
+			args: args{ctx: context.TestContext(nil)},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/base1/mib.yml", []byte("name: base\ntag: '1'\nbuildArgs:\n  VARIANT: '2'"), 0644)
+				afero.WriteFile(ctx.FS, "/app/base1/Dockerfile", []byte("FROM debian:latest"), 0644)
+				afero.WriteFile(ctx.FS, "/app/base2/mib.yml", []byte("name: base\ntag: '2'"), 0644)
+				afero.WriteFile(ctx.FS, "/app/base2/Dockerfile", []byte("FROM debian:latest"), 0644)
+				afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("ARG VARIANT=1\nFROM base:${VARIANT}"), 0644)
+			},
+			want: nil,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, "parent of foo:0.1 changes with the build args inherited from it", i...)
+			},
+		},
+		{
+			name: "FailDuplicateImage",
+			args: args{ctx: context.TestContext(nil)},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/test/mib.yml", []byte("name: test\ntag: 0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/test/Dockerfile", []byte("FROM debian:latest"), 0644)
+				afero.WriteFile(ctx.FS, "/app/test2/mib.yml", []byte("name: test2\ntag: 0.1\nalias: [{name: test, tag: '0.1'}]"), 0644)
+				afero.WriteFile(ctx.FS, "/app/test2/Dockerfile", []byte("FROM debian:latest"), 0644)
+			},
+			want: nil,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, "image test:0.1 is declared in both /app/test/mib.yml and /app/test2/mib.yml", i...)
+			},
+		},
+		{
+			name: "FailDependencyCycle",
+			args: args{ctx: context.TestContext(nil)},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/foo/mib.yml", []byte("name: foo\ntag: 0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/foo/Dockerfile", []byte("FROM bar:0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/bar/mib.yml", []byte("name: bar\ntag: 0.1"), 0644)
+				afero.WriteFile(ctx.FS, "/app/bar/Dockerfile", []byte("FROM foo:0.1"), 0644)
+			},
+			want: nil,
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.EqualError(t, err, "dependency cycle detected: /app/bar/mib.yml -> /app/foo/mib.yml -> /app/bar/mib.yml", i...)
+			},
+		},
 		{
 			name: "CheckOkWithOneImageFail",
 			args: args{ctx: context.TestContext(nil)},
@@ -259,6 +513,91 @@ func TestLoadImages(t *testing.T) {
 	}
 }
 
+func TestLoadImages_SuccessNestedWorkingDirWatch(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/repo/images"
+	_ = ctx.FS.MkdirAll("/repo/.git", 0755)
+	_ = afero.WriteFile(ctx.FS, "/repo/images/foo/mib.yml", []byte("name: foo\ntag: 0.1\nwatch: [\"/scripts/\"]"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/repo/images/foo/Dockerfile", []byte("FROM debian:latest"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/repo/images/bar/mib.yml", []byte("name: bar\ntag: 0.1"), 0644)
+	_ = afero.WriteFile(ctx.FS, "/repo/images/bar/Dockerfile", []byte("FROM debian:latest"), 0644)
+
+	images, err := LoadImages(ctx)
+	assert.NoError(t, err)
+	images.FlagChanged(RemoveExtExcludePath(ctx.WorkingDir, ctx.Config.Build.ExtensionExclude, []string{"../scripts/build.sh"}))
+	for _, image := range images {
+		assert.Equal(t, "/repo", image.RepoDir)
+		assert.Equal(t, image.Name == "foo", image.HasToBuild, image.Name)
+	}
+}
+
+func Test_findRepoDir(t *testing.T) {
+	ctx := context.TestContext(nil)
+	ctx.WorkingDir = "/repo/images"
+	assert.Equal(t, "", findRepoDir(ctx))
+
+	_ = afero.WriteFile(ctx.FS, "/repo/.git", []byte("gitdir: /worktrees/repo"), 0644)
+	assert.Equal(t, "/repo", findRepoDir(ctx))
+
+	ctx.RepoDir = "/tmp/export"
+	assert.Equal(t, "/tmp/export", findRepoDir(ctx))
+}
+
+func Test_loadDockerIgnore(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   *types.Image
+		preRun  func(ctx *context.Context)
+		want    []string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "SuccessWithoutIgnoreFile",
+			image:   &types.Image{Path: "/app/foo"},
+			preRun:  func(ctx *context.Context) {},
+			want:    nil,
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "SuccessWithDockerIgnore",
+			image: &types.Image{Path: "/app/foo"},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/foo/.dockerignore", []byte("# comment\ntests\n*.md\n"), 0644)
+			},
+			want:    []string{"tests", "*.md", "!Dockerfile", "!.dockerignore", "!mib.yml"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "SuccessWithDockerfileIgnorePrecedence",
+			image: &types.Image{Path: "/app/foo", Dockerfile: "docker/prod.Dockerfile", Context: ".."},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/.dockerignore", []byte("tests"), 0644)
+				afero.WriteFile(ctx.FS, "/app/foo/docker/prod.Dockerfile.dockerignore", []byte("docs"), 0644)
+			},
+			want:    []string{"docs", "!foo/docker/prod.Dockerfile", "!foo/docker/prod.Dockerfile.dockerignore", "!foo/mib.yml"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "SuccessWithContextOutsideImageDir",
+			image: &types.Image{Path: "/app/foo", Context: "../shared"},
+			preRun: func(ctx *context.Context) {
+				afero.WriteFile(ctx.FS, "/app/shared/.dockerignore", []byte("tests"), 0644)
+			},
+			want:    []string{"tests", "!.dockerignore"},
+			wantErr: assert.NoError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TestContext(nil)
+			tt.preRun(ctx)
+			err := loadDockerIgnore(ctx, tt.image)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, tt.image.DockerIgnore)
+		})
+	}
+}
+
 func Test_orderDependencyImages(t *testing.T) {
 
 	image1 := &types.Image{ImageName: types.ImageName{Name: "test", Tag: "0.1"}, Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}}
@@ -267,6 +606,13 @@ func Test_orderDependencyImages(t *testing.T) {
 	imageChild := &types.Image{ImageName: types.ImageName{Name: "bar", Tag: "1.0"}, Parent: &types.Image{ImageName: types.ImageName{Name: "test", Tag: "0.1"}}}
 	imageChildChild := &types.Image{ImageName: types.ImageName{Name: "bar2", Tag: "1.1"}, Parent: &types.Image{ImageName: types.ImageName{Name: "bar", Tag: "1.0"}}}
 
+	imageTools := &types.Image{ImageName: types.ImageName{Name: "tools", Tag: "0.1"}, Path: "/app/tools", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}}
+	imageWithDependency := &types.Image{ImageName: types.ImageName{Name: "baz", Tag: "0.1"}, Path: "/app/baz", Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}, Dependencies: types.Images{&types.Image{ImageName: types.ImageName{Name: "tools", Tag: "0.1"}}, &types.Image{ImageName: types.ImageName{Name: "external", Tag: "1.0"}}}}
+
+	imageWithAlias := &types.Image{ImageName: types.ImageName{Name: "foo/bar", Tag: "0.1"}, Alias: []types.ImageName{{Name: "foo2/bar", Tag: "develop"}}, Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}}
+	imageChildOfAlias := &types.Image{ImageName: types.ImageName{Name: "foo/baz", Tag: "0.1"}, Parent: &types.Image{ImageName: types.ImageName{Name: "foo2/bar", Tag: "develop"}}}
+	imageDependencyOfAlias := &types.Image{ImageName: types.ImageName{Name: "foo/qux", Tag: "0.1"}, Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}, Dependencies: types.Images{&types.Image{ImageName: types.ImageName{Name: "foo2/bar", Tag: "develop"}}}}
+
 	imagePlatform := &types.Image{ImageName: types.ImageName{Name: "test", Tag: "0.1"}, Platforms: []string{"foo", "bar"}, Parent: &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}}}
 	imageChildPlatform := &types.Image{ImageName: types.ImageName{Name: "bar", Tag: "1.0"}, Parent: &types.Image{ImageName: types.ImageName{Name: "test", Tag: "0.1"}}}
 	tests := []struct {
@@ -326,6 +672,29 @@ func Test_orderDependencyImages(t *testing.T) {
 				image2,
 			},
 		},
+		{
+			name: "CheckOkWithDependency",
+			imagesToSort: types.Images{
+				imageWithDependency,
+				imageTools,
+			},
+			want: types.Images{
+				imageWithDependency,
+				imageTools,
+			},
+		},
+		{
+			name: "CheckOkWithAlias",
+			imagesToSort: types.Images{
+				imageChildOfAlias,
+				imageWithAlias,
+				imageDependencyOfAlias,
+			},
+			want: types.Images{
+				imageWithAlias,
+				imageDependencyOfAlias,
+			},
+		},
 		{
 			name: "CheckOkWithPlatform",
 			imagesToSort: types.Images{
@@ -344,6 +713,15 @@ func Test_orderDependencyImages(t *testing.T) {
 			}
 		})
 	}
+	assert.Same(t, imageTools, imageWithDependency.Dependencies[0])
+	assert.Equal(t, "external", imageWithDependency.Dependencies[1].Name)
+	assert.Equal(t, types.Images{imageWithDependency}, imageTools.Dependents)
+	assert.Same(t, imageWithAlias, imageChildOfAlias.Parent)
+	assert.True(t, imageChildOfAlias.HasLocalParent)
+	assert.Equal(t, "foo2/bar:develop", imageChildOfAlias.ParentAlias)
+	assert.Equal(t, types.Images{imageChildOfAlias}, imageWithAlias.Children)
+	assert.Same(t, imageWithAlias, imageDependencyOfAlias.Dependencies[0])
+	assert.Equal(t, "", imageChild.ParentAlias)
 }
 
 func TestRemoveExtExcludePath(t *testing.T) {
@@ -384,6 +762,15 @@ func TestRemoveExtExcludePath(t *testing.T) {
 			},
 			want: []string{"/app/bar/Dockerfile"},
 		},
+		{
+			name: "SuccessWithPatterns",
+			args: args{
+				workingDir:       "/app",
+				extensionExclude: ".md, docs/, **/tests/*.sh,*.log,!keep.log",
+				filesUpdated:     []string{"foo/file.md", "docs/index.html", "foo/docs/index.html", "foo/tests/run.sh", "foo/tests/Dockerfile", "foo/app.log", "foo/keep.log", "bar/Dockerfile"},
+			},
+			want: []string{"/app/foo/tests/Dockerfile", "/app/foo/keep.log", "/app/bar/Dockerfile"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -3,13 +3,15 @@ package main
 import (
 	"github.com/alexandreh2ag/mib/cli"
 	"github.com/alexandreh2ag/mib/context"
+	"os"
 )
 
 func main() {
 	ctx := context.DefaultContext()
 	rootCmd := cli.GetRootCmd(ctx)
 
+	// cobra already prints the error, the exit code tells whether anything failed
 	if err := rootCmd.Execute(); err != nil {
-		panic(err)
+		os.Exit(1)
 	}
 }
@@ -1,9 +1,13 @@
 package printer
 
 import (
+	"fmt"
 	"github.com/alexandreh2ag/mib/types"
 	"github.com/fatih/color"
 	"github.com/xlab/treeprint"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func DisplayImagesTree(images types.Images) string {
@@ -28,3 +32,85 @@ func displayImages(images types.Images, tree treeprint.Tree) {
 		}
 	}
 }
+
+// DisplayImagesRemovedTree lists images removed or moved with their previous and new dirs.
+func DisplayImagesRemovedTree(removed types.ImagesRemoved) string {
+	tree := treeprint.NewWithRoot("removed")
+	for _, image := range removed {
+		path := image.Path
+		if image.IsRenamed() {
+			path = fmt.Sprintf("%s -> %s", image.Path, image.NewPath)
+		}
+		tree.AddNode(fmt.Sprintf("%s (%s)", color.RedString(strings.Join(image.Names, ", ")), path))
+	}
+	return tree.String()
+}
+
+// DisplayBuildSummary lists the outcome of images scheduled to build, in build order, with their build duration.
+func DisplayBuildSummary(images types.Images) string {
+	counts := map[types.BuildStatus]int{}
+	lines := []string{}
+	for _, image := range images.GetBuildOrder() {
+		result := image.BuildResult
+		if result == nil {
+			continue
+		}
+		counts[result.Status]++
+		name := image.GetFullName()
+		switch result.Status {
+		case types.BuildStatusBuilt:
+			lines = append(lines, fmt.Sprintf("%s built in %s", color.GreenString(name), result.Duration.Round(time.Millisecond)))
+		case types.BuildStatusFailed:
+			lines = append(lines, fmt.Sprintf("%s failed in %s: %v", color.RedString(name), result.Duration.Round(time.Millisecond), result.Err))
+		case types.BuildStatusCancelled:
+			lines = append(lines, fmt.Sprintf("%s cancelled, %s failed", color.YellowString(name), result.FailedImage.GetFullName()))
+		default:
+			lines = append(lines, fmt.Sprintf("%s %s", name, result.Status))
+		}
+	}
+	tree := treeprint.NewWithRoot(fmt.Sprintf(
+		"build summary: %d built, %d failed, %d cancelled, %d skipped",
+		counts[types.BuildStatusBuilt], counts[types.BuildStatusFailed], counts[types.BuildStatusCancelled], counts[types.BuildStatusSkipped],
+	))
+	for _, line := range lines {
+		tree.AddNode(line)
+	}
+	return tree.String()
+}
+
+// DisplayBuildPlan lists the builds of the plan in order, with their dir, tags, platforms, command and pushed tags,
+// then the local images removed.
+func DisplayBuildPlan(plan types.BuildPlan) string {
+	tree := treeprint.NewWithRoot(fmt.Sprintf("build plan: %d images", len(plan.Builds)))
+	for i, step := range plan.Builds {
+		node := tree.AddBranch(fmt.Sprintf("%d. %s (%s)", i+1, color.GreenString(step.Name), step.Path))
+		node.AddNode(fmt.Sprintf("dir: %s", step.Dir))
+		node.AddNode(fmt.Sprintf("tags: %s", strings.Join(step.Tags, ", ")))
+		if len(step.Platforms) > 0 {
+			node.AddNode(fmt.Sprintf("platforms: %s", strings.Join(step.Platforms, ", ")))
+		}
+		if step.Parent != "" {
+			node.AddNode(fmt.Sprintf("parent: %s", step.Parent))
+		}
+		node.AddNode(fmt.Sprintf("command: %s", joinCommand(step.Command)))
+		if len(step.Push) > 0 {
+			node.AddNode(fmt.Sprintf("push: %s", strings.Join(step.Push, ", ")))
+		}
+	}
+	if len(plan.Remove) > 0 {
+		tree.AddNode(fmt.Sprintf("remove local images: %s", color.RedString(strings.Join(plan.Remove, ", "))))
+	}
+	return tree.String()
+}
+
+// joinCommand joins the command line, quoting empty arguments and those with spaces.
+func joinCommand(args []string) string {
+	quoted := []string{}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = strconv.Quote(arg)
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
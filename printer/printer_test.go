@@ -1,10 +1,12 @@
 package printer
 
 import (
+	"errors"
 	"github.com/alexandreh2ag/mib/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/xlab/treeprint"
 	"testing"
+	"time"
 )
 
 func Test_displayImages(t *testing.T) {
@@ -71,3 +73,88 @@ func TestDisplayImagesTree(t *testing.T) {
 		})
 	}
 }
+
+func TestDisplayImagesRemovedTree(t *testing.T) {
+	tests := []struct {
+		name    string
+		removed types.ImagesRemoved
+		want    string
+	}{
+		{
+			name:    "SuccessEmpty",
+			removed: types.ImagesRemoved{},
+			want:    "removed\n",
+		},
+		{
+			name: "SuccessRemovedAndRenamed",
+			removed: types.ImagesRemoved{
+				{Names: []string{"foo:0.1", "foo:latest"}, Path: "foo"},
+				{Names: []string{"bar:0.1"}, Path: "bar", NewPath: "baz/bar"},
+			},
+			want: "removed\n├── foo:0.1, foo:latest (foo)\n└── bar:0.1 (bar -> baz/bar)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DisplayImagesRemovedTree(tt.removed))
+		})
+	}
+}
+
+func TestDisplayBuildSummary(t *testing.T) {
+	failed := &types.Image{ImageName: types.ImageName{Name: "foo", Tag: "0.1"}, BuildResult: &types.BuildResult{Status: types.BuildStatusFailed, Duration: 3 * time.Second, Err: errors.New("error")}}
+	cancelled := &types.Image{ImageName: types.ImageName{Name: "foo-child", Tag: "0.1"}, HasLocalParent: true, Parent: failed, BuildResult: &types.BuildResult{Status: types.BuildStatusCancelled, FailedImage: failed}}
+	failed.Children = types.Images{cancelled}
+	tests := []struct {
+		name   string
+		images types.Images
+		want   string
+	}{
+		{
+			name:   "SuccessEmpty",
+			images: types.Images{&types.Image{ImageName: types.ImageName{Name: "bar", Tag: "0.1"}}},
+			want:   "build summary: 0 built, 0 failed, 0 cancelled, 0 skipped\n",
+		},
+		{
+			name: "SuccessAllStatus",
+			images: types.Images{
+				&types.Image{ImageName: types.ImageName{Name: "bar", Tag: "0.1"}, BuildResult: &types.BuildResult{Status: types.BuildStatusBuilt, Duration: 61500 * time.Millisecond}},
+				failed,
+				&types.Image{ImageName: types.ImageName{Name: "baz", Tag: "0.1"}, BuildResult: &types.BuildResult{Status: types.BuildStatusSkipped}},
+				&types.Image{ImageName: types.ImageName{Name: "qux", Tag: "0.1"}},
+			},
+			want: "build summary: 1 built, 1 failed, 1 cancelled, 1 skipped\n" +
+				"├── bar:0.1 built in 1m1.5s\n" +
+				"├── foo:0.1 failed in 3s: error\n" +
+				"├── foo-child:0.1 cancelled, foo:0.1 failed\n" +
+				"└── baz:0.1 skipped\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DisplayBuildSummary(tt.images))
+		})
+	}
+}
+
+func TestDisplayBuildPlan(t *testing.T) {
+	plan := types.BuildPlan{Builds: []types.BuildStep{
+		{Name: "base:0.1", Path: "base", Dir: "/app/base", Tags: []string{"base:0.1", "base:latest"}, Platforms: []string{"linux/amd64", "linux/arm64"}, Parent: "alpine:3.19", Command: []string{"docker", "build", "--build-arg", "MESSAGE=hello world", "."}, Push: []string{"base:0.1", "base:latest"}},
+		{Name: "child:0.1", Path: "child", Dir: "/app/child", Tags: []string{"child:0.1"}, Platforms: []string{}, Command: []string{"docker", "build", "."}, Push: []string{}},
+	}, Remove: []string{"old:0.1", "old:latest"}}
+	want := "build plan: 2 images\n" +
+		"├── 1. base:0.1 (base)\n" +
+		"│   ├── dir: /app/base\n" +
+		"│   ├── tags: base:0.1, base:latest\n" +
+		"│   ├── platforms: linux/amd64, linux/arm64\n" +
+		"│   ├── parent: alpine:3.19\n" +
+		"│   ├── command: docker build --build-arg \"MESSAGE=hello world\" .\n" +
+		"│   └── push: base:0.1, base:latest\n" +
+		"├── 2. child:0.1 (child)\n" +
+		"│   ├── dir: /app/child\n" +
+		"│   ├── tags: child:0.1\n" +
+		"│   └── command: docker build .\n" +
+		"└── remove local images: old:0.1, old:latest\n"
+	assert.Equal(t, want, DisplayBuildPlan(plan))
+	assert.Equal(t, "build plan: 0 images\n", DisplayBuildPlan(types.BuildPlan{}))
+}
@@ -0,0 +1,102 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/state"
+	typesState "github.com/alexandreh2ag/mib/types/state"
+	"github.com/spf13/afero"
+	"os"
+	"path/filepath"
+)
+
+const (
+	KeyStore = "file"
+)
+
+func init() {
+	state.StoreFnFactory[KeyStore] = CreateFileStore
+}
+
+func CreateFileStore(ctx *context.Context) (typesState.Store, error) {
+	path := ctx.Config.Build.State.Path
+	if path == "" {
+		defaultPath, err := DefaultPath(ctx.WorkingDir)
+		if err != nil {
+			return nil, err
+		}
+		path = defaultPath
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ctx.WorkingDir, path)
+	}
+	return &StoreFile{fs: ctx.FS, path: path}, nil
+}
+
+// DefaultPath returns the state file of the working dir in the user cache dir, outside the checkout so it is never committed.
+func DefaultPath(workingDir string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find a default build state path, set build.state.path: %v", err)
+	}
+	sum := sha256.Sum256([]byte(workingDir))
+	return filepath.Join(cacheDir, "mib", fmt.Sprintf("state-%x.json", sum[:8])), nil
+}
+
+var _ typesState.Store = &StoreFile{}
+
+// StoreFile keeps the build state of all images in a JSON file indexed by image name.
+type StoreFile struct {
+	fs   afero.Fs
+	path string
+}
+
+func (s StoreFile) Type() string {
+	return KeyStore
+}
+
+func (s StoreFile) Get(name string) (*typesState.BuildState, error) {
+	states, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	if buildState, ok := states[name]; ok {
+		return &buildState, nil
+	}
+	return nil, nil
+}
+
+func (s StoreFile) Save(name string, buildState typesState.BuildState) error {
+	states, err := s.read()
+	if err != nil {
+		return err
+	}
+	states[name] = buildState
+	content, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = s.fs.MkdirAll(filepath.Dir(s.path), 0755)
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(s.fs, s.path, append(content, '\n'), 0644)
+}
+
+func (s StoreFile) read() (map[string]typesState.BuildState, error) {
+	states := map[string]typesState.BuildState{}
+	content, err := afero.ReadFile(s.fs, s.path)
+	if os.IsNotExist(err) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(content, &states)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse build state %s with error: %v", s.path, err)
+	}
+	return states, nil
+}
@@ -0,0 +1,87 @@
+package file
+
+import (
+	"github.com/alexandreh2ag/mib/context"
+	typesState "github.com/alexandreh2ag/mib/types/state"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCreateFileStore(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "SuccessDefaultPath", path: "", want: "/cache/mib/state-f53b52ad6d21cceb.json"},
+		{name: "SuccessRelativePath", path: "state/mib.json", want: "/app/state/mib.json"},
+		{name: "SuccessAbsolutePath", path: "/var/lib/mib.json", want: "/var/lib/mib.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", "/cache")
+			ctx := context.TestContext(nil)
+			ctx.Config.Build.State.Path = tt.path
+			got, err := CreateFileStore(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, &StoreFile{fs: ctx.FS, path: tt.want}, got)
+		})
+	}
+}
+
+func TestCreateFileStore_ErrorDefaultPath(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("HOME", "")
+	ctx := context.TestContext(nil)
+	got, err := CreateFileStore(ctx)
+	assert.Nil(t, got)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not find a default build state path, set build.state.path")
+}
+
+func TestStoreFile_Type(t *testing.T) {
+	assert.Equal(t, KeyStore, StoreFile{}.Type())
+}
+
+func TestStoreFile_Get_SuccessNotExist(t *testing.T) {
+	s := StoreFile{fs: afero.NewMemMapFs(), path: "/app/.mib-state.json"}
+	got, err := s.Get("foo:0.1")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestStoreFile_SaveAndGet_Success(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	s := StoreFile{fs: fs, path: "/app/.mib-state.json"}
+	builtAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	err := s.Save("foo:0.1", typesState.BuildState{Commit: "abc", Digest: "sha256:123", BuiltAt: builtAt})
+	assert.NoError(t, err)
+	err = s.Save("bar:0.1", typesState.BuildState{Commit: "def", BuiltAt: builtAt})
+	assert.NoError(t, err)
+
+	got, err := s.Get("foo:0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, &typesState.BuildState{Commit: "abc", Digest: "sha256:123", BuiltAt: builtAt}, got)
+	got, err = s.Get("bar:0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, &typesState.BuildState{Commit: "def", BuiltAt: builtAt}, got)
+	got, err = s.Get("baz:0.1")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	content, _ := afero.ReadFile(fs, "/app/.mib-state.json")
+	assert.Contains(t, string(content), "\"foo:0.1\": {\n    \"commit\": \"abc\",\n    \"digest\": \"sha256:123\",")
+}
+
+func TestStoreFile_Get_ErrorParse(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "/app/.mib-state.json", []byte("{wrong"), 0644)
+	s := StoreFile{fs: fs, path: "/app/.mib-state.json"}
+	_, err := s.Get("foo:0.1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "could not parse build state /app/.mib-state.json with error")
+	err = s.Save("foo:0.1", typesState.BuildState{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,10 @@
+package state
+
+import (
+	"github.com/alexandreh2ag/mib/context"
+	"github.com/alexandreh2ag/mib/types/state"
+)
+
+var StoreFnFactory = map[string]CreateStoreFn{}
+
+type CreateStoreFn = func(ctx *context.Context) (state.Store, error)
@@ -89,6 +89,74 @@ func TestGenerateReadmeIndex(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGenerateReadmeImages(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ctx := &context.Context{
+		FS:     afero.NewMemMapFs(),
+		Config: &cfg,
+	}
+	ctx.WorkingDir = "/test"
+	afs := &afero.Afero{Fs: ctx.FS}
+	tools := &types.Image{ImageName: types.ImageName{Name: "registry.example.com/tools", Tag: "0.1"}, Path: "/test/tools", RelativeDir: "tools"}
+	image := &types.Image{
+		ImageName:    types.ImageName{Name: "registry.example.com/foo", Tag: "0.1"},
+		Path:         "/test/foo/v1",
+		RelativeDir:  "foo/v1",
+		Parent:       &types.Image{ImageName: types.ImageName{Name: "debian", Tag: "latest"}},
+		Dependencies: types.Images{tools, &types.Image{ImageName: types.ImageName{Name: "golang", Tag: "1.22"}}},
+		BuildArgs:    map[string]string{"VERSION": "${CI_COMMIT_TAG}"},
+	}
+	tools.Dependents = types.Images{image}
+	_ = ctx.FS.MkdirAll(image.Path, os.FileMode(0775))
+	_ = ctx.FS.MkdirAll(tools.Path, os.FileMode(0775))
+
+	err := GenerateReadmeImages(ctx, types.Images{tools, image})
+	assert.NoError(t, err)
+	content, err := afs.ReadFile("/test/foo/v1/README.md")
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "## Dependencies\n- [registry.example.com/tools:0.1](../../tools/README.md)\n- [golang:1.22](https://hub.docker.com/_/golang)\n")
+	assert.Contains(t, string(content), "## Build Args\n| Arg Name | Value |\n| -------- | ----- |\n| VERSION  | ${CI_COMMIT_TAG}  |\n")
+	content, err = afs.ReadFile("/test/tools/README.md")
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "## Used by\n- [registry.example.com/foo:0.1](../foo/v1/README.md)\n")
+}
+
+func TestGenerateReadmeImages_ParentAlias(t *testing.T) {
+	cfg := config.DefaultConfig()
+	ctx := &context.Context{
+		FS:     afero.NewMemMapFs(),
+		Config: &cfg,
+	}
+	ctx.WorkingDir = "/test"
+	afs := &afero.Afero{Fs: ctx.FS}
+	parent := &types.Image{
+		ImageName:   types.ImageName{Name: "registry.example.com/foo/bar", Tag: "0.1"},
+		Alias:       []types.ImageName{{Name: "registry.example.com/foo2/bar", Tag: "develop"}},
+		Path:        "/test/bar",
+		RelativeDir: "bar",
+	}
+	child := &types.Image{
+		ImageName:      types.ImageName{Name: "registry.example.com/foo/baz", Tag: "0.1"},
+		Path:           "/test/baz",
+		RelativeDir:    "baz",
+		Parent:         parent,
+		ParentAlias:    "registry.example.com/foo2/bar:develop",
+		HasLocalParent: true,
+	}
+	parent.Children = types.Images{child}
+	_ = ctx.FS.MkdirAll(parent.Path, os.FileMode(0775))
+	_ = ctx.FS.MkdirAll(child.Path, os.FileMode(0775))
+
+	err := GenerateReadmeImages(ctx, types.Images{parent, child})
+	assert.NoError(t, err)
+	content, err := afs.ReadFile("/test/baz/README.md")
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "## Parents\n- [registry.example.com/foo/bar:0.1](../bar/README.md) (as registry.example.com/foo2/bar:develop)\n")
+	content, err = afs.ReadFile("/test/bar/README.md")
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "## Children\n- [registry.example.com/foo/baz:0.1](../baz/README.md) (as registry.example.com/foo2/bar:develop)\n")
+}
+
 func TestGetTemplateFileContent(t *testing.T) {
 	cfg := config.DefaultConfig()
 	ctx := &context.Context{
@@ -0,0 +1,23 @@
+package types
+
+import "time"
+
+// BuildStatus is the outcome of an image scheduled to build.
+type BuildStatus string
+
+const (
+	BuildStatusBuilt  BuildStatus = "built"
+	BuildStatusFailed BuildStatus = "failed"
+	// BuildStatusCancelled is set on images depending on a failed image.
+	BuildStatusCancelled BuildStatus = "cancelled"
+	// BuildStatusSkipped is set on images not started since the build stopped on a failure.
+	BuildStatusSkipped BuildStatus = "skipped"
+)
+
+type BuildResult struct {
+	Status   BuildStatus
+	Duration time.Duration
+	Err      error
+	// FailedImage is the failed image which cancelled the build.
+	FailedImage *Image
+}
@@ -15,6 +15,10 @@ type BuilderImage interface {
 	Type() string
 	BuildImages(images types.Images, pushImages bool) error
 	Build(image *types.Image, pushImages bool) error
+	GetBuildCommand(image *types.Image, pushImages bool) ([]string, error)
 	PushImages(images types.Images) error
 	Push(tag string) error
+	RemoveImages(names []string) error
+	GetDigest(name string) (string, error)
+	GetPublishedLabel(name string, label string) (string, error)
 }
@@ -1,12 +1,37 @@
 package types
 
+import (
+	"github.com/moby/patternmatcher"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DefaultDockerfile = "Dockerfile"
+	LabelContentHash  = "mib.content-hash"
+)
+
 type ImageName struct {
 	Name string `yaml:"name" validate:"required"`
 	Tag  string `yaml:"tag" validate:"required"`
+	// Digest is only set on parents pinned by digest in a Dockerfile.
+	Digest string `yaml:"-"`
 }
 
 func (imn ImageName) GetFullName() string {
-	return imn.Name + ":" + imn.Tag
+	if imn.Digest == "" {
+		return imn.Name + ":" + imn.Tag
+	}
+	if imn.Tag == "" {
+		return imn.Name + "@" + imn.Digest
+	}
+	return imn.Name + ":" + imn.Tag + "@" + imn.Digest
+}
+
+func (imn ImageName) GetDigest() string {
+	return imn.Digest
 }
 
 func (imn ImageName) GetName() string {
@@ -18,18 +43,39 @@ func (imn ImageName) GetTag() string {
 }
 
 type Image struct {
-	ImageName        `yaml:",inline" validate:"required"`
-	Alias            []ImageName `yaml:"alias" validate:"omitempty,dive"`
-	Path             string
-	RelativeDir      string
-	Parent           *Image `validate:"-"`
+	ImageName   `yaml:",inline" validate:"required"`
+	Alias       []ImageName `yaml:"alias" validate:"omitempty,dive"`
+	Path        string
+	RelativeDir string
+	Parent      *Image `validate:"-"`
+	// ParentAlias is the alias of the local parent used in the Dockerfile, empty when its main name is used.
+	ParentAlias      string `validate:"-"`
+	Dependencies     Images `validate:"-"`
+	Dependents       Images `validate:"-"`
 	Children         Images `validate:"omitempty,dive"`
 	HasLocalParent   bool
 	HasToBuild       bool
 	HasParentToBuild bool
+	IsBuilt          bool
+	ContentHash      string            `yaml:"-"`
+	BuildResult      *BuildResult      `yaml:"-" validate:"-"`
 	EnvVariables     map[string]string `yaml:"envvars"`
 	Packages         map[string]string `yaml:"packages"`
+	BuildArgs        map[string]string `yaml:"buildArgs"`
 	Platforms        []string          `yaml:"platforms" validate:"platform-parent"`
+	// Dockerfile and Context are relative to the image dir, Target is the multi-stage target to build.
+	Dockerfile string `yaml:"dockerfile"`
+	Context    string `yaml:"context"`
+	Target     string `yaml:"target"`
+	// Watch globs are relative to the git repository root (RepoDir), Ignore globs to the image dir.
+	Watch  []string `yaml:"watch"`
+	Ignore []string `yaml:"ignore"`
+	// ClaimNested makes the image also rebuilt on changes of image dirs nested in its own dir.
+	ClaimNested bool `yaml:"claimNested"`
+	// DockerIgnore holds the .dockerignore patterns of the build context.
+	DockerIgnore []string `yaml:"-" validate:"-"`
+	// RepoDir is the root dir of the git repository the image has been loaded from.
+	RepoDir string `yaml:"-" validate:"-"`
 	//Platforms []string `yaml:"platforms" validate:"-"`
 }
 
@@ -43,6 +89,126 @@ func (im Image) GetParents() Images {
 	return parents
 }
 
+func (im Image) GetDockerfile() string {
+	if im.Dockerfile == "" {
+		return DefaultDockerfile
+	}
+	return im.Dockerfile
+}
+
+func (im Image) GetDockerfilePath() string {
+	return filepath.Join(im.Path, im.GetDockerfile())
+}
+
+func (im Image) GetContext() string {
+	if im.Context == "" {
+		return "."
+	}
+	return im.Context
+}
+
+func (im Image) GetContextPath() string {
+	return filepath.Join(im.Path, im.GetContext())
+}
+
+// GetWatchedPaths returns the paths whose changes require to rebuild the image.
+func (im Image) GetWatchedPaths() []string {
+	paths := []string{im.Path}
+	contextPath := im.GetContextPath()
+	if contextPath != im.Path {
+		paths = append(paths, contextPath)
+	}
+	dockerfilePath := im.GetDockerfilePath()
+	if filepath.Dir(dockerfilePath) != im.Path && filepath.Dir(dockerfilePath) != contextPath {
+		paths = append(paths, dockerfilePath)
+	}
+	return paths
+}
+
+// IsWatching returns true when a change on the path requires to rebuild the image. Files of the
+// image dir are only watched when the image owns them (see Images.GetOwner) or claims nested dirs.
+func (im Image) IsWatching(path string, owner *Image) bool {
+	if im.IsIgnored(path) {
+		return false
+	}
+	if IsInDir(path, im.Path) && ((owner != nil && owner.Path == im.Path) || im.ClaimNested) {
+		return true
+	}
+	for _, watchedPath := range im.GetWatchedPaths()[1:] {
+		if IsInDir(path, watchedPath) {
+			return true
+		}
+	}
+	return MatchPatterns(im.Watch, im.GetRepoDir(), path)
+}
+
+// GetRootDir returns the mib working dir the image has been loaded from.
+func (im Image) GetRootDir() string {
+	if im.RelativeDir == "" || im.RelativeDir == "." {
+		return im.Path
+	}
+	return filepath.Clean(strings.TrimSuffix(im.Path, im.RelativeDir))
+}
+
+// GetRepoDir returns the root dir of the git repository, or the mib working dir when it is unknown.
+func (im Image) GetRepoDir() string {
+	if im.RepoDir != "" {
+		return im.RepoDir
+	}
+	return im.GetRootDir()
+}
+
+// IsIgnored returns true when the path matches the ignore globs or is in the
+// build context but kept out of it by .dockerignore.
+func (im Image) IsIgnored(path string) bool {
+	if MatchPatterns(im.Ignore, im.Path, path) {
+		return true
+	}
+	return im.IsDockerIgnored(path)
+}
+
+// IsDockerIgnored returns true when the path is in the build context but kept
+// out of it by .dockerignore.
+func (im Image) IsDockerIgnored(path string) bool {
+	if len(im.DockerIgnore) == 0 {
+		return false
+	}
+	relativePath, err := filepath.Rel(im.GetContextPath(), path)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, "../") {
+		return false
+	}
+	ignored, err := patternmatcher.MatchesOrParentMatches(relativePath, im.DockerIgnore)
+	return err == nil && ignored
+}
+
+func (im Image) IsLocal() bool {
+	return im.Path != ""
+}
+
+func (im Image) GetLocalDependencies() Images {
+	dependencies := Images{}
+	for _, dependency := range im.Dependencies {
+		if dependency.IsLocal() {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies
+}
+
+// GetRequirementsToBuild returns the local parent and local dependencies which have to be built before the image.
+func (im Image) GetRequirementsToBuild() Images {
+	requirements := Images{}
+	if im.HasLocalParent && im.Parent.HasToBuild {
+		requirements = append(requirements, im.Parent)
+	}
+	for _, dependency := range im.GetLocalDependencies() {
+		if dependency.HasToBuild {
+			requirements = append(requirements, dependency)
+		}
+	}
+	return requirements
+}
+
 func (im Image) GetAllEnvVar() map[string]string {
 	envVars := make(map[string]string)
 	var images Images
@@ -71,6 +237,33 @@ func (im Image) GetAllPackages() map[string]string {
 	return packagesVars
 }
 
+func (im Image) GetAllBuildArgs() map[string]string {
+	buildArgs := make(map[string]string)
+	var images Images
+	images = append(images, &im)
+	images = append(images, im.GetParents()...)
+	for i := len(images) - 1; i >= 0; i-- {
+		for k, v := range images[i].BuildArgs {
+			buildArgs[k] = v
+		}
+	}
+
+	return buildArgs
+}
+
+// GetBuildArgsValues returns all build args with env var references (like ${CI_COMMIT_SHA}) expanded.
+func (im Image) GetBuildArgsValues() map[string]string {
+	return ExpandEnvValues(im.GetAllBuildArgs())
+}
+
+func ExpandEnvValues(values map[string]string) map[string]string {
+	expanded := make(map[string]string, len(values))
+	for k, v := range values {
+		expanded[k] = os.ExpandEnv(v)
+	}
+	return expanded
+}
+
 func (im Image) GetNames() []string {
 	var names []string
 
@@ -81,3 +274,46 @@ func (im Image) GetNames() []string {
 	}
 	return names
 }
+
+// MatchSelector returns true when selector is the name of the image or of one of its aliases, with or without tag,
+// or the dir of the image, absolute or relative to workingDir.
+func (im Image) MatchSelector(workingDir string, selector string) bool {
+	for _, imageName := range append([]ImageName{im.ImageName}, im.Alias...) {
+		if selector == imageName.Name || selector == imageName.Name+":"+imageName.Tag {
+			return true
+		}
+	}
+	path := selector
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+	return im.Path != "" && filepath.Clean(path) == im.Path
+}
+
+// MatchGlob returns true when the name of the image or of one of its aliases, with or without tag, or its dir
+// relative to the working dir matches one of globs.
+func (im Image) MatchGlob(globs []string) bool {
+	values := []string{filepath.ToSlash(im.RelativeDir)}
+	for _, imageName := range append([]ImageName{im.ImageName}, im.Alias...) {
+		values = append(values, imageName.Name, imageName.Name+":"+imageName.Tag)
+	}
+	for _, glob := range globs {
+		for _, value := range values {
+			if matched, _ := path.Match(glob, value); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasName returns true when the name (without digest) is the name of the image or one of its aliases.
+func (im Image) HasName(name ImageName) bool {
+	fullName := ImageName{Name: name.Name, Tag: name.Tag}.GetFullName()
+	for _, imageName := range im.GetNames() {
+		if imageName == fullName {
+			return true
+		}
+	}
+	return false
+}
@@ -7,8 +7,9 @@ import (
 
 func TestImageName_GetFullName(t *testing.T) {
 	type fields struct {
-		Name string
-		Tag  string
+		Name   string
+		Tag    string
+		Digest string
 	}
 	tests := []struct {
 		name   string
@@ -27,13 +28,31 @@ func TestImageName_GetFullName(t *testing.T) {
 			},
 			want: "test:0.1",
 		},
+		{
+			name: "SuccessWithDigest",
+			fields: fields{
+				Name:   "test",
+				Tag:    "0.1",
+				Digest: "sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b",
+			},
+			want: "test:0.1@sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b",
+		},
+		{
+			name: "SuccessWithDigestOnly",
+			fields: fields{
+				Name:   "test",
+				Digest: "sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b",
+			},
+			want: "test@sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			im := Image{
 				ImageName: ImageName{
-					Name: tt.fields.Name,
-					Tag:  tt.fields.Tag,
+					Name:   tt.fields.Name,
+					Tag:    tt.fields.Tag,
+					Digest: tt.fields.Digest,
 				},
 			}
 			assert.Equalf(t, tt.want, im.GetFullName(), "GetFullName()")
@@ -196,3 +215,197 @@ func TestImageName_GetTag(t *testing.T) {
 	im := ImageName{Tag: "0.1"}
 	assert.Equal(t, "0.1", im.GetTag())
 }
+
+func TestImageName_GetDigest(t *testing.T) {
+	im := ImageName{Digest: "sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b"}
+	assert.Equal(t, "sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b", im.GetDigest())
+}
+
+func TestImage_GetLocalDependencies(t *testing.T) {
+	local := &Image{ImageName: ImageName{Name: "tools", Tag: "0.1"}, Path: "/app/tools"}
+	external := &Image{ImageName: ImageName{Name: "external", Tag: "0.1"}}
+	im := Image{Dependencies: Images{external, local}}
+	assert.Equal(t, Images{local}, im.GetLocalDependencies())
+	assert.True(t, local.IsLocal())
+	assert.False(t, external.IsLocal())
+}
+
+func TestImage_GetRequirementsToBuild(t *testing.T) {
+	parent := &Image{ImageName: ImageName{Name: "base", Tag: "0.1"}, Path: "/app/base", HasToBuild: true}
+	tools := &Image{ImageName: ImageName{Name: "tools", Tag: "0.1"}, Path: "/app/tools", HasToBuild: true}
+	unchanged := &Image{ImageName: ImageName{Name: "unchanged", Tag: "0.1"}, Path: "/app/unchanged"}
+	external := &Image{ImageName: ImageName{Name: "external", Tag: "0.1"}}
+	im := Image{Parent: parent, HasLocalParent: true, Dependencies: Images{external, unchanged, tools}}
+	assert.Equal(t, Images{parent, tools}, im.GetRequirementsToBuild())
+
+	parent.HasToBuild = false
+	assert.Equal(t, Images{tools}, im.GetRequirementsToBuild())
+}
+
+func TestImage_GetAllBuildArgs(t *testing.T) {
+	parent := &Image{BuildArgs: map[string]string{"BASE_TAG": "3.19", "VERSION": "1.0"}}
+	im := Image{Parent: parent, BuildArgs: map[string]string{"VERSION": "2.0", "COMMIT": "${MIB_TEST_COMMIT}"}}
+	want := map[string]string{"BASE_TAG": "3.19", "VERSION": "2.0", "COMMIT": "${MIB_TEST_COMMIT}"}
+	assert.Equal(t, want, im.GetAllBuildArgs())
+}
+
+func TestImage_GetBuildArgsValues(t *testing.T) {
+	t.Setenv("MIB_TEST_COMMIT", "abcdef")
+	parent := &Image{BuildArgs: map[string]string{"BASE_TAG": "3.19"}}
+	im := Image{Parent: parent, BuildArgs: map[string]string{"COMMIT": "${MIB_TEST_COMMIT}", "UNKNOWN": "$MIB_TEST_UNKNOWN"}}
+	want := map[string]string{"BASE_TAG": "3.19", "COMMIT": "abcdef", "UNKNOWN": ""}
+	assert.Equal(t, want, im.GetBuildArgsValues())
+}
+
+func TestImage_MatchSelector(t *testing.T) {
+	image := Image{ImageName: ImageName{Name: "foo/bar", Tag: "0.1"}, Alias: []ImageName{{Name: "foo2/bar", Tag: "develop"}}, Path: "/app/images/bar"}
+	tests := []struct {
+		name     string
+		selector string
+		want     bool
+	}{
+		{name: "SuccessName", selector: "foo/bar", want: true},
+		{name: "SuccessNameWithTag", selector: "foo/bar:0.1", want: true},
+		{name: "SuccessAlias", selector: "foo2/bar:develop", want: true},
+		{name: "SuccessRelativePath", selector: "images/bar/", want: true},
+		{name: "SuccessRelativePathDot", selector: "./images/bar", want: true},
+		{name: "SuccessAbsolutePath", selector: "/app/images/bar", want: true},
+		{name: "FailOtherTag", selector: "foo/bar:0.2"},
+		{name: "FailOtherName", selector: "foo"},
+		{name: "FailParentPath", selector: "images"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, image.MatchSelector("/app", tt.selector))
+		})
+	}
+}
+
+func TestImage_MatchGlob(t *testing.T) {
+	image := Image{ImageName: ImageName{Name: "foo/bar", Tag: "0.1"}, Alias: []ImageName{{Name: "foo2/bar", Tag: "develop"}}, RelativeDir: "images/bar"}
+	tests := []struct {
+		name  string
+		globs []string
+		want  bool
+	}{
+		{name: "SuccessName", globs: []string{"foo/*"}, want: true},
+		{name: "SuccessNameWithTag", globs: []string{"foo/bar:0.*"}, want: true},
+		{name: "SuccessAlias", globs: []string{"*/bar:develop"}, want: true},
+		{name: "SuccessDir", globs: []string{"other", "images/*"}, want: true},
+		{name: "FailNoMatch", globs: []string{"foo", "bar:*"}},
+		{name: "FailEmpty", globs: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, image.MatchGlob(tt.globs))
+		})
+	}
+}
+
+func TestImage_HasName(t *testing.T) {
+	image := Image{ImageName: ImageName{Name: "foo/bar", Tag: "0.1"}, Alias: []ImageName{{Name: "foo2/bar", Tag: "develop"}}}
+	assert.True(t, image.HasName(ImageName{Name: "foo/bar", Tag: "0.1"}))
+	assert.True(t, image.HasName(ImageName{Name: "foo2/bar", Tag: "develop"}))
+	assert.True(t, image.HasName(ImageName{Name: "foo2/bar", Tag: "develop", Digest: "sha256:0000000000000000000000000000000000000000000000000000000000000000"}))
+	assert.False(t, image.HasName(ImageName{Name: "foo2/bar", Tag: "0.1"}))
+	assert.False(t, image.HasName(ImageName{Name: "foo/bar", Tag: "develop"}))
+}
+
+func TestImage_GetDockerfilePath(t *testing.T) {
+	assert.Equal(t, "/app/foo/Dockerfile", Image{Path: "/app/foo"}.GetDockerfilePath())
+	assert.Equal(t, "/app/foo/docker/prod.Dockerfile", Image{Path: "/app/foo", Dockerfile: "docker/prod.Dockerfile"}.GetDockerfilePath())
+}
+
+func TestImage_GetContextPath(t *testing.T) {
+	assert.Equal(t, "/app/foo", Image{Path: "/app/foo"}.GetContextPath())
+	assert.Equal(t, "/app/shared", Image{Path: "/app/foo", Context: "../shared"}.GetContextPath())
+}
+
+func TestImage_GetWatchedPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		image Image
+		want  []string
+	}{
+		{
+			name:  "SuccessDefault",
+			image: Image{Path: "/app/foo"},
+			want:  []string{"/app/foo"},
+		},
+		{
+			name:  "SuccessDockerfileInImageDir",
+			image: Image{Path: "/app/foo", Dockerfile: "prod.Dockerfile"},
+			want:  []string{"/app/foo"},
+		},
+		{
+			name:  "SuccessContextOutsideImageDir",
+			image: Image{Path: "/app/foo", Context: "../shared"},
+			want:  []string{"/app/foo", "/app/shared"},
+		},
+		{
+			name:  "SuccessDockerfileOutsideImageDir",
+			image: Image{Path: "/app/foo", Context: "..", Dockerfile: "../docker/foo.Dockerfile"},
+			want:  []string{"/app/foo", "/app", "/app/docker/foo.Dockerfile"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.image.GetWatchedPaths())
+		})
+	}
+}
+
+func TestImage_IsWatching(t *testing.T) {
+	image := Image{Path: "/app/foo", Context: "../shared"}
+	assert.True(t, image.IsWatching("/app/foo/Dockerfile", &image))
+	assert.True(t, image.IsWatching("/app/shared/config.yml", &image))
+	assert.False(t, image.IsWatching("/app/bar/Dockerfile", &image))
+}
+
+func TestImage_IsIgnored(t *testing.T) {
+	image := Image{Path: "/app/foo", DockerIgnore: []string{"tests", "**/*.md", "!Dockerfile", "!mib.yml"}}
+	assert.True(t, image.IsIgnored("/app/foo/tests/unit/foo_test.sh"))
+	assert.True(t, image.IsIgnored("/app/foo/docs/README.md"))
+	assert.False(t, image.IsIgnored("/app/foo/Dockerfile"))
+	assert.False(t, image.IsIgnored("/app/foo/bin/run.sh"))
+	assert.False(t, image.IsIgnored("/app/bar/tests/foo_test.sh"))
+	assert.False(t, Image{Path: "/app/foo"}.IsIgnored("/app/foo/tests/foo_test.sh"))
+	assert.False(t, image.IsWatching("/app/foo/tests/foo_test.sh", &image))
+	assert.True(t, image.IsWatching("/app/foo/bin/run.sh", &image))
+}
+
+func TestImage_IsDockerIgnored(t *testing.T) {
+	image := Image{Path: "/app/foo", Ignore: []string{"bin"}, DockerIgnore: []string{"tests"}}
+	assert.True(t, image.IsDockerIgnored("/app/foo/tests/unit/foo_test.sh"))
+	assert.False(t, image.IsDockerIgnored("/app/foo/bin/run.sh"))
+	assert.False(t, image.IsDockerIgnored("/app/bar/tests/foo_test.sh"))
+}
+
+func TestImage_GetRootDir(t *testing.T) {
+	assert.Equal(t, "/app", Image{Path: "/app/foo/v1", RelativeDir: "foo/v1"}.GetRootDir())
+	assert.Equal(t, "/app", Image{Path: "/app", RelativeDir: "."}.GetRootDir())
+	assert.Equal(t, "/app/foo", Image{Path: "/app/foo"}.GetRootDir())
+}
+
+func TestImage_IsWatching_WithGlobs(t *testing.T) {
+	image := Image{Path: "/app/foo", RelativeDir: "foo", Watch: []string{"scripts/", "shared/*.conf"}, Ignore: []string{"tests/", "*.md"}}
+	assert.True(t, image.IsWatching("/app/foo/Dockerfile", &image))
+	assert.True(t, image.IsWatching("/app/scripts/build.sh", &image))
+	assert.True(t, image.IsWatching("/app/shared/nginx.conf", &image))
+	assert.False(t, image.IsWatching("/app/shared/README", &image))
+	assert.False(t, image.IsWatching("/app/foo/tests/run.sh", &image))
+	assert.False(t, image.IsWatching("/app/foo/docs/index.md", &image))
+	assert.True(t, image.IsWatching("/app/scripts/README.md", &image))
+	assert.False(t, image.IsWatching("/app/bar/Dockerfile", &image))
+}
+
+func TestImage_IsWatching_WithGlobsInNestedWorkingDir(t *testing.T) {
+	image := Image{Path: "/repo/images/foo", RelativeDir: "foo", RepoDir: "/repo", Watch: []string{"/scripts/"}}
+	assert.True(t, image.IsWatching("/repo/scripts/build.sh", &image))
+	assert.False(t, image.IsWatching("/repo/images/scripts/build.sh", &image))
+}
+
+func TestImage_GetRepoDir(t *testing.T) {
+	assert.Equal(t, "/repo", Image{Path: "/repo/images/foo", RelativeDir: "foo", RepoDir: "/repo"}.GetRepoDir())
+	assert.Equal(t, "/repo/images", Image{Path: "/repo/images/foo", RelativeDir: "foo"}.GetRepoDir())
+}
@@ -1,6 +1,10 @@
 package types
 
-import "strings"
+import (
+	"fmt"
+	"path"
+	"slices"
+)
 
 type Images []*Image
 
@@ -38,17 +42,190 @@ func (ims Images) GetImagesToBuild() Images {
 	return images
 }
 
+// FlagAll flags all images and their children to build.
+func (ims Images) FlagAll() {
+	for _, image := range ims.GetAll() {
+		image.HasToBuild = true
+	}
+}
+
 func (ims Images) FlagChanged(pathToBuild []string) {
+	ims.flagChanged(pathToBuild)
+	ims.flagDependentsToBuild()
+}
+
+func (ims Images) flagChanged(pathToBuild []string) {
+	images := ims.GetAll()
 	for _, path := range pathToBuild {
-		for _, image := range ims {
-			if strings.Contains(path, image.Path) {
+		owner := images.GetOwner(path)
+		for _, image := range images {
+			if image.IsWatching(path, owner) {
 				image.HasToBuild = true
-			} else if image.HasLocalParent && image.Parent.HasToBuild {
+			}
+		}
+	}
+}
+
+// FlagChangedSince flags images with a file changed since their own last build, changedSince returns these paths
+// and false when the image has never been built, so it has to be built anyway.
+func (ims Images) FlagChangedSince(changedSince func(image *Image) ([]string, bool, error)) error {
+	images := ims.GetAll()
+	for _, image := range images {
+		paths, built, err := changedSince(image)
+		if err != nil {
+			return err
+		}
+		if !built {
+			image.HasToBuild = true
+			continue
+		}
+		for _, path := range paths {
+			if image.IsWatching(path, images.GetOwner(path)) {
 				image.HasToBuild = true
+				break
 			}
-			image.Children.FlagChanged(pathToBuild)
 		}
 	}
+	ims.flagDependentsToBuild()
+	return nil
+}
+
+// FlagHashChanged flags images whose content hash differs from the published one, hasChanged compares them.
+func (ims Images) FlagHashChanged(hasChanged func(image *Image) (bool, error)) error {
+	for _, image := range ims.GetBuildOrder() {
+		changed, err := hasChanged(image)
+		if err != nil {
+			return err
+		}
+		if changed {
+			image.HasToBuild = true
+		}
+	}
+	ims.flagDependentsToBuild()
+	return nil
+}
+
+// FlagSelected flags images matching one of selectors (see Image.MatchSelector) to build, with their local parents
+// and dependencies when withParents, and their children and dependents when withChildren.
+func (ims Images) FlagSelected(workingDir string, selectors []string, withParents bool, withChildren bool) error {
+	all := ims.GetAll()
+	for _, selector := range selectors {
+		matched := false
+		for _, image := range all {
+			if image.MatchSelector(workingDir, selector) {
+				matched = true
+				image.HasToBuild = true
+				if withParents {
+					flagRequirementsToBuild(image, map[*Image]bool{})
+				}
+				if withChildren {
+					flagDescendantsToBuild(image, map[*Image]bool{})
+				}
+			}
+		}
+		if !matched {
+			return fmt.Errorf("no image matches %s", selector)
+		}
+	}
+	return nil
+}
+
+func flagRequirementsToBuild(image *Image, visited map[*Image]bool) {
+	requirements := image.GetLocalDependencies()
+	if image.HasLocalParent {
+		requirements = append(requirements, image.Parent)
+	}
+	for _, requirement := range requirements {
+		if !visited[requirement] {
+			visited[requirement] = true
+			requirement.HasToBuild = true
+			flagRequirementsToBuild(requirement, visited)
+		}
+	}
+}
+
+func flagDescendantsToBuild(image *Image, visited map[*Image]bool) {
+	descendants := append(Images{}, image.Children...)
+	descendants = append(descendants, image.Dependents...)
+	for _, descendant := range descendants {
+		if !visited[descendant] {
+			visited[descendant] = true
+			descendant.HasToBuild = true
+			flagDescendantsToBuild(descendant, visited)
+		}
+	}
+}
+
+// UnflagExcluded removes from images to build those matching one of globs (see Image.MatchGlob).
+func (ims Images) UnflagExcluded(globs []string) error {
+	for _, glob := range globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("exclude pattern %s is not valid: %v", glob, err)
+		}
+	}
+	for _, image := range ims.GetAll() {
+		if image.HasToBuild && image.MatchGlob(globs) {
+			image.HasToBuild = false
+		}
+	}
+	return nil
+}
+
+// GetOwner returns the image with the deepest dir containing the path, or nil when no image dir contains it.
+func (ims Images) GetOwner(path string) *Image {
+	var owner *Image
+	for _, image := range ims {
+		if IsInDir(path, image.Path) && (owner == nil || len(image.Path) > len(owner.Path)) {
+			owner = image
+		}
+	}
+	return owner
+}
+
+// flagDependentsToBuild flags images whose local parent or local dependency
+// has to be built, which is needed since dependencies live outside the tree.
+func (ims Images) flagDependentsToBuild() {
+	for _, image := range ims.GetBuildOrder() {
+		if image.HasToBuild {
+			continue
+		}
+		if image.HasLocalParent && image.Parent.HasToBuild {
+			image.HasToBuild = true
+			continue
+		}
+		for _, dependency := range image.GetLocalDependencies() {
+			if dependency.HasToBuild {
+				image.HasToBuild = true
+				break
+			}
+		}
+	}
+}
+
+// GetBuildOrder returns all images of the tree ordered so that every image
+// comes after its local parent and its local dependencies.
+func (ims Images) GetBuildOrder() Images {
+	all := ims.GetAll()
+	ordered := Images{}
+	visited := map[*Image]bool{}
+	var visit func(image *Image)
+	visit = func(image *Image) {
+		if visited[image] || !slices.Contains(all, image) {
+			return
+		}
+		visited[image] = true
+		if image.HasLocalParent {
+			visit(image.Parent)
+		}
+		for _, dependency := range image.GetLocalDependencies() {
+			visit(dependency)
+		}
+		ordered = append(ordered, image)
+	}
+	for _, image := range all {
+		visit(image)
+	}
+	return ordered
 }
 
 func (ims Images) GetAllNames(includeChildren bool) []string {
@@ -61,3 +238,44 @@ func (ims Images) GetAllNames(includeChildren bool) []string {
 	}
 	return names
 }
+
+// GetBuildLevels groups images to build by dependency level, images of a level only require images of previous
+// levels to be built first, so images of the same level can be built in parallel.
+func (ims Images) GetBuildLevels() []Images {
+	levels := []Images{}
+	imageLevels := map[*Image]int{}
+	for _, image := range ims.GetBuildOrder() {
+		if !image.HasToBuild {
+			continue
+		}
+		level := 0
+		for _, requirement := range image.GetRequirementsToBuild() {
+			if requirementLevel, ok := imageLevels[requirement]; ok && requirementLevel >= level {
+				level = requirementLevel + 1
+			}
+		}
+		imageLevels[image] = level
+		if level == len(levels) {
+			levels = append(levels, Images{})
+		}
+		levels[level] = append(levels[level], image)
+	}
+	return levels
+}
+
+// GetLevelsPlan returns the images to build grouped by dependency level, with the images each one requires.
+func (ims Images) GetLevelsPlan() LevelsPlan {
+	plan := LevelsPlan{Levels: []PlanLevel{}}
+	for level, images := range ims.GetBuildLevels() {
+		planLevel := PlanLevel{Level: level, Images: []PlanImage{}}
+		for _, image := range images {
+			planLevel.Images = append(planLevel.Images, PlanImage{
+				Name:    image.GetFullName(),
+				Path:    image.RelativeDir,
+				Parents: image.GetRequirementsToBuild().GetAllNames(false),
+			})
+		}
+		plan.Levels = append(plan.Levels, planLevel)
+	}
+	return plan
+}
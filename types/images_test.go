@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -127,6 +128,14 @@ func TestImages_FlagChanged(t *testing.T) {
 		child.Parent = parent
 		return Images{parent}
 	}
+	successDependencyToBuildFn := func() Images {
+		tools := &Image{ImageName: ImageName{Name: "tools", Tag: "0.1"}, Path: "/tools"}
+		dependent := &Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/foo", Dependencies: Images{tools}}
+		child := &Image{ImageName: ImageName{Name: "foo-child", Tag: "0.1"}, Path: "/foo-child", HasLocalParent: true, Parent: dependent}
+		dependent.Children = Images{child}
+		tools.Dependents = Images{dependent}
+		return Images{dependent, tools}
+	}
 	tests := []struct {
 		name        string
 		ims         Images
@@ -140,6 +149,92 @@ func TestImages_FlagChanged(t *testing.T) {
 				assert.True(t, true)
 			},
 		},
+		{
+			name: "SuccessSiblingWithSamePrefix",
+			ims: Images{
+				&Image{ImageName: ImageName{Name: "debian", Tag: "0.1"}, Path: "/repo/debian"},
+				&Image{ImageName: ImageName{Name: "debian-nginx", Tag: "0.1"}, Path: "/repo/debian-nginx/v1"},
+			},
+			pathToBuild: []string{"/repo/debian-nginx/v1/Dockerfile"},
+			fnCheck: func(t *testing.T, images Images) {
+				assert.False(t, images[0].HasToBuild)
+				assert.True(t, images[1].HasToBuild)
+			},
+		},
+		{
+			name: "SuccessNestedImageDirs",
+			ims: Images{
+				&Image{ImageName: ImageName{Name: "app", Tag: "0.1"}, Path: "/repo/app"},
+				&Image{ImageName: ImageName{Name: "app-worker", Tag: "0.1"}, Path: "/repo/app/worker"},
+				&Image{ImageName: ImageName{Name: "app-worker-debug", Tag: "0.1"}, Path: "/repo/app/worker/debug"},
+			},
+			pathToBuild: []string{"/repo/app/worker/Dockerfile"},
+			fnCheck: func(t *testing.T, images Images) {
+				assert.False(t, images[0].HasToBuild)
+				assert.True(t, images[1].HasToBuild)
+				assert.False(t, images[2].HasToBuild)
+			},
+		},
+		{
+			name: "SuccessNestedImageDirsClaimed",
+			ims: Images{
+				&Image{ImageName: ImageName{Name: "app", Tag: "0.1"}, Path: "/repo/app", ClaimNested: true},
+				&Image{ImageName: ImageName{Name: "app-worker", Tag: "0.1"}, Path: "/repo/app/worker"},
+				&Image{ImageName: ImageName{Name: "app-worker-debug", Tag: "0.1"}, Path: "/repo/app/worker/debug"},
+			},
+			pathToBuild: []string{"/repo/app/worker/debug/Dockerfile"},
+			fnCheck: func(t *testing.T, images Images) {
+				assert.True(t, images[0].HasToBuild)
+				assert.False(t, images[1].HasToBuild)
+				assert.True(t, images[2].HasToBuild)
+			},
+		},
+		{
+			name: "SuccessWatchedGlobChanged",
+			ims: Images{
+				&Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", RelativeDir: "foo", Watch: []string{"scripts/"}},
+				&Image{ImageName: ImageName{Name: "bar", Tag: "0.1"}, Path: "/app/bar", RelativeDir: "bar", Ignore: []string{"*.sh"}},
+			},
+			pathToBuild: []string{"/app/scripts/build.sh", "/app/bar/entrypoint.sh"},
+			fnCheck: func(t *testing.T, images Images) {
+				assert.True(t, images[0].HasToBuild)
+				assert.False(t, images[1].HasToBuild)
+			},
+		},
+		{
+			name: "SuccessDockerIgnoredChanged",
+			ims: Images{
+				&Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", DockerIgnore: []string{"tests", "*.md"}},
+				&Image{ImageName: ImageName{Name: "bar", Tag: "0.1"}, Path: "/app/bar", DockerIgnore: []string{"tests"}},
+			},
+			pathToBuild: []string{"/app/foo/tests/foo_test.sh", "/app/foo/README.md", "/app/bar/Dockerfile"},
+			fnCheck: func(t *testing.T, images Images) {
+				assert.False(t, images[0].HasToBuild)
+				assert.True(t, images[1].HasToBuild)
+			},
+		},
+		{
+			name: "SuccessContextChanged",
+			ims: Images{
+				&Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", Context: "../shared"},
+				&Image{ImageName: ImageName{Name: "bar", Tag: "0.1"}, Path: "/app/bar"},
+			},
+			pathToBuild: []string{"/app/shared/config.yml"},
+			fnCheck: func(t *testing.T, images Images) {
+				assert.True(t, images[0].HasToBuild)
+				assert.False(t, images[1].HasToBuild)
+			},
+		},
+		{
+			name:        "SuccessDependencyToBuild",
+			ims:         successDependencyToBuildFn(),
+			pathToBuild: []string{"/tools/Dockerfile"},
+			fnCheck: func(t *testing.T, images Images) {
+				assert.True(t, images[1].HasToBuild, "Dependency Image")
+				assert.True(t, images[0].HasToBuild, "Dependent Image")
+				assert.True(t, images[0].Children[0].HasToBuild, "Child of dependent Image")
+			},
+		},
 		{
 			name: "SuccessOneImageToBuild",
 			ims: Images{
@@ -201,6 +296,62 @@ func TestImages_FlagChanged(t *testing.T) {
 	}
 }
 
+func TestImages_FlagChangedSince(t *testing.T) {
+	base := &Image{ImageName: ImageName{Name: "base", Tag: "0.1"}, Path: "/app/base"}
+	child := &Image{ImageName: ImageName{Name: "child", Tag: "0.1"}, Path: "/app/child", HasLocalParent: true, Parent: base}
+	base.Children = Images{child}
+	never := &Image{ImageName: ImageName{Name: "never", Tag: "0.1"}, Path: "/app/never"}
+	same := &Image{ImageName: ImageName{Name: "same", Tag: "0.1"}, Path: "/app/same"}
+	images := Images{base, never, same}
+
+	err := images.FlagChangedSince(func(image *Image) ([]string, bool, error) {
+		switch image.Name {
+		case "base":
+			return []string{"/app/base/Dockerfile"}, true, nil
+		case "never":
+			return nil, false, nil
+		}
+		return []string{"/app/base/Dockerfile"}, true, nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, base.HasToBuild)
+	assert.True(t, child.HasToBuild)
+	assert.True(t, never.HasToBuild)
+	assert.False(t, same.HasToBuild)
+}
+
+func TestImages_FlagChangedSince_Error(t *testing.T) {
+	images := Images{&Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo"}}
+	err := images.FlagChangedSince(func(image *Image) ([]string, bool, error) {
+		return nil, false, errors.New("error")
+	})
+	assert.EqualError(t, err, "error")
+}
+
+func TestImages_FlagHashChanged(t *testing.T) {
+	base := &Image{ImageName: ImageName{Name: "base", Tag: "0.1"}, Path: "/app/base"}
+	child := &Image{ImageName: ImageName{Name: "child", Tag: "0.1"}, Path: "/app/child", HasLocalParent: true, Parent: base}
+	base.Children = Images{child}
+	same := &Image{ImageName: ImageName{Name: "same", Tag: "0.1"}, Path: "/app/same"}
+	images := Images{base, same}
+
+	err := images.FlagHashChanged(func(image *Image) (bool, error) {
+		return image.Name == "base", nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, base.HasToBuild)
+	assert.True(t, child.HasToBuild)
+	assert.False(t, same.HasToBuild)
+}
+
+func TestImages_FlagHashChanged_Error(t *testing.T) {
+	images := Images{&Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo"}}
+	err := images.FlagHashChanged(func(image *Image) (bool, error) {
+		return false, errors.New("error")
+	})
+	assert.EqualError(t, err, "error")
+}
+
 func TestImages_GetAll_SuccessEmpty(t *testing.T) {
 	images := Images{}
 	want := Images{}
@@ -223,6 +374,140 @@ func TestImages_GetAll_SuccessWithout(t *testing.T) {
 	assert.Equal(t, want, images.GetAll())
 }
 
+func TestImages_GetBuildOrder(t *testing.T) {
+	tools := &Image{ImageName: ImageName{Name: "tools", Tag: "0.1"}, Path: "/tools"}
+	toolsChild := &Image{ImageName: ImageName{Name: "tools-child", Tag: "0.1"}, Path: "/tools-child", HasLocalParent: true, Parent: tools}
+	tools.Children = Images{toolsChild}
+	external := &Image{ImageName: ImageName{Name: "external", Tag: "0.1"}}
+	foo := &Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/foo", Dependencies: Images{toolsChild, external}}
+	fooChild := &Image{ImageName: ImageName{Name: "foo-child", Tag: "0.1"}, Path: "/foo-child", HasLocalParent: true, Parent: foo}
+	foo.Children = Images{fooChild}
+	bar := &Image{ImageName: ImageName{Name: "bar", Tag: "0.1"}, Path: "/bar"}
+
+	images := Images{foo, bar, tools}
+	want := Images{tools, toolsChild, foo, fooChild, bar}
+	assert.Equal(t, want, images.GetBuildOrder())
+}
+
+func TestImages_GetBuildLevels(t *testing.T) {
+	tools := &Image{ImageName: ImageName{Name: "tools", Tag: "0.1"}, Path: "/tools", HasToBuild: true}
+	toolsChild := &Image{ImageName: ImageName{Name: "tools-child", Tag: "0.1"}, Path: "/tools-child", HasLocalParent: true, Parent: tools, HasToBuild: true}
+	tools.Children = Images{toolsChild}
+	foo := &Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/foo", Dependencies: Images{toolsChild}, HasToBuild: true}
+	unchanged := &Image{ImageName: ImageName{Name: "unchanged", Tag: "0.1"}, Path: "/unchanged"}
+	unchangedChild := &Image{ImageName: ImageName{Name: "unchanged-child", Tag: "0.1"}, Path: "/unchanged-child", HasLocalParent: true, Parent: unchanged, HasToBuild: true}
+	unchanged.Children = Images{unchangedChild}
+
+	images := Images{foo, unchanged, tools}
+	want := []Images{{tools, unchangedChild}, {toolsChild}, {foo}}
+	assert.Equal(t, want, images.GetBuildLevels())
+}
+
+func TestImages_GetBuildLevels_SuccessEmpty(t *testing.T) {
+	images := Images{&Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/foo"}}
+	assert.Equal(t, []Images{}, images.GetBuildLevels())
+}
+
+func TestImages_FlagAll(t *testing.T) {
+	child := &Image{ImageName: ImageName{Name: "foo-child", Tag: "0.1"}, Path: "/foo-child"}
+	images := Images{
+		&Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/foo", Children: Images{child}},
+		&Image{ImageName: ImageName{Name: "bar", Tag: "0.1"}, Path: "/bar"},
+	}
+	images.FlagAll()
+	assert.True(t, images[0].HasToBuild)
+	assert.True(t, images[1].HasToBuild)
+	assert.True(t, child.HasToBuild)
+}
+
+func TestImages_GetLevelsPlan(t *testing.T) {
+	tools := &Image{ImageName: ImageName{Name: "tools", Tag: "0.1"}, Path: "/app/tools", RelativeDir: "tools", HasToBuild: true}
+	base := &Image{ImageName: ImageName{Name: "base", Tag: "0.1"}, Path: "/app/base", RelativeDir: "base", HasToBuild: true}
+	child := &Image{ImageName: ImageName{Name: "child", Tag: "0.1"}, Path: "/app/child", RelativeDir: "child", HasLocalParent: true, Parent: base, Dependencies: Images{tools}, HasToBuild: true}
+	base.Children = Images{child}
+	images := Images{base, tools}
+
+	want := LevelsPlan{Levels: []PlanLevel{
+		{Level: 0, Images: []PlanImage{
+			{Name: "base:0.1", Path: "base", Parents: []string{}},
+			{Name: "tools:0.1", Path: "tools", Parents: []string{}},
+		}},
+		{Level: 1, Images: []PlanImage{
+			{Name: "child:0.1", Path: "child", Parents: []string{"base:0.1", "tools:0.1"}},
+		}},
+	}}
+	assert.Equal(t, want, images.GetLevelsPlan())
+	assert.Equal(t, LevelsPlan{Levels: []PlanLevel{}}, Images{}.GetLevelsPlan())
+}
+
+func createSelectionImages() (Images, map[string]*Image) {
+	base := &Image{ImageName: ImageName{Name: "base", Tag: "0.1"}, Path: "/app/base", RelativeDir: "base"}
+	tools := &Image{ImageName: ImageName{Name: "tools", Tag: "0.1"}, Path: "/app/tools", RelativeDir: "tools"}
+	php := &Image{ImageName: ImageName{Name: "php", Tag: "8.3"}, Path: "/app/php", RelativeDir: "php", HasLocalParent: true, Parent: base, Dependencies: Images{tools}}
+	app := &Image{ImageName: ImageName{Name: "app", Tag: "0.1"}, Path: "/app/app", RelativeDir: "app", HasLocalParent: true, Parent: php}
+	worker := &Image{ImageName: ImageName{Name: "worker", Tag: "0.1"}, Path: "/app/worker", RelativeDir: "worker", Dependencies: Images{php}}
+	other := &Image{ImageName: ImageName{Name: "other", Tag: "0.1"}, Path: "/app/other", RelativeDir: "other"}
+	base.Children = Images{php}
+	php.Children = Images{app}
+	tools.Dependents = Images{php}
+	php.Dependents = Images{worker}
+	byName := map[string]*Image{"base": base, "tools": tools, "php": php, "app": app, "worker": worker, "other": other}
+	return Images{base, tools, worker, other}, byName
+}
+
+func TestImages_FlagSelected(t *testing.T) {
+	tests := []struct {
+		name         string
+		selectors    []string
+		withParents  bool
+		withChildren bool
+		want         []string
+	}{
+		{name: "SuccessOnlySelected", selectors: []string{"php", "other"}, want: []string{"other", "php"}},
+		{name: "SuccessPath", selectors: []string{"php/"}, want: []string{"php"}},
+		{name: "SuccessWithParents", selectors: []string{"app:0.1"}, withParents: true, want: []string{"app", "base", "php", "tools"}},
+		{name: "SuccessWithChildren", selectors: []string{"php"}, withChildren: true, want: []string{"app", "php", "worker"}},
+		{name: "SuccessWithParentsAndChildren", selectors: []string{"php"}, withParents: true, withChildren: true, want: []string{"app", "base", "php", "tools", "worker"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images, byName := createSelectionImages()
+			err := images.FlagSelected("/app", tt.selectors, tt.withParents, tt.withChildren)
+			assert.NoError(t, err)
+			got := []string{}
+			for name, image := range byName {
+				if image.HasToBuild {
+					got = append(got, name)
+				}
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestImages_FlagSelected_Error(t *testing.T) {
+	images, _ := createSelectionImages()
+	err := images.FlagSelected("/app", []string{"php", "unknown"}, false, false)
+	assert.EqualError(t, err, "no image matches unknown")
+}
+
+func TestImages_UnflagExcluded(t *testing.T) {
+	images, byName := createSelectionImages()
+	images.FlagAll()
+	err := images.UnflagExcluded([]string{"base", "w*"})
+	assert.NoError(t, err)
+	assert.False(t, byName["base"].HasToBuild)
+	assert.False(t, byName["worker"].HasToBuild)
+	assert.True(t, byName["php"].HasToBuild)
+	assert.True(t, byName["app"].HasToBuild)
+}
+
+func TestImages_UnflagExcluded_Error(t *testing.T) {
+	images, _ := createSelectionImages()
+	err := images.UnflagExcluded([]string{"["})
+	assert.EqualError(t, err, "exclude pattern [ is not valid: syntax error in pattern")
+}
+
 func TestImages_GetAllNames_SuccessEmpty(t *testing.T) {
 	images := Images{}
 	assert.Equal(t, []string{}, images.GetAllNames(false))
@@ -245,3 +530,15 @@ func TestImages_GetAllNames_SuccessWithIncludeChildren(t *testing.T) {
 	want := []string{"foo:0.1", "foo-child:0.1", "bar:0.1"}
 	assert.Equal(t, want, images.GetAllNames(true))
 }
+
+func TestImages_GetOwner(t *testing.T) {
+	parent := &Image{ImageName: ImageName{Name: "app", Tag: "0.1"}, Path: "/repo/app"}
+	nested := &Image{ImageName: ImageName{Name: "app-worker", Tag: "0.1"}, Path: "/repo/app/worker"}
+	sibling := &Image{ImageName: ImageName{Name: "app-api", Tag: "0.1"}, Path: "/repo/app-api"}
+	images := Images{nested, parent, sibling}
+	assert.Same(t, parent, images.GetOwner("/repo/app/Dockerfile"))
+	assert.Same(t, nested, images.GetOwner("/repo/app/worker/Dockerfile"))
+	assert.Same(t, nested, images.GetOwner("/repo/app/worker"))
+	assert.Same(t, sibling, images.GetOwner("/repo/app-api/Dockerfile"))
+	assert.Nil(t, images.GetOwner("/repo/scripts/build.sh"))
+}
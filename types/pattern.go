@@ -0,0 +1,37 @@
+package types
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"path/filepath"
+	"strings"
+)
+
+// MatchPatterns returns true when the path matches the gitignore-style patterns,
+// the patterns being relative to the domain dir.
+func MatchPatterns(patterns []string, domain string, path string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	domainParts := splitPath(domain)
+	gitPatterns := make([]gitignore.Pattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		gitPatterns = append(gitPatterns, gitignore.ParsePattern(pattern, domainParts))
+	}
+	return gitignore.NewMatcher(gitPatterns).Match(splitPath(path), false)
+}
+
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+}
+
+// IsInDir returns true when the path is the dir itself or is inside it.
+func IsInDir(path string, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	relativePath, err := filepath.Rel(dir, path)
+	return err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, "../") && !filepath.IsAbs(relativePath)
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMatchPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		domain   string
+		path     string
+		want     bool
+	}{
+		{name: "SuccessNoPattern", domain: "/app", path: "/app/foo", want: false},
+		{name: "SuccessDir", patterns: []string{"scripts/"}, domain: "/app", path: "/app/scripts/build.sh", want: true},
+		{name: "SuccessAnchored", patterns: []string{"/scripts"}, domain: "/app", path: "/app/foo/scripts/build.sh", want: false},
+		{name: "SuccessGlob", patterns: []string{"shared/**/*.conf"}, domain: "/app", path: "/app/shared/nginx/site.conf", want: true},
+		{name: "SuccessOutsideDomain", patterns: []string{"scripts/"}, domain: "/app/foo", path: "/app/scripts/build.sh", want: false},
+		{name: "SuccessNegation", patterns: []string{"*.sh", "!keep.sh"}, domain: "/app", path: "/app/keep.sh", want: false},
+		{name: "SuccessRelative", patterns: []string{"*.md"}, path: "foo/README.md", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchPatterns(tt.patterns, tt.domain, tt.path))
+		})
+	}
+}
+
+func TestIsInDir(t *testing.T) {
+	assert.True(t, IsInDir("/repo/debian", "/repo/debian"))
+	assert.True(t, IsInDir("/repo/debian/Dockerfile", "/repo/debian"))
+	assert.True(t, IsInDir("/repo/debian/v1/Dockerfile", "/repo/debian/"))
+	assert.False(t, IsInDir("/repo/debian-nginx/v1/Dockerfile", "/repo/debian"))
+	assert.False(t, IsInDir("/repo/Dockerfile", "/repo/debian"))
+	assert.False(t, IsInDir("/repo/debian", ""))
+}
@@ -0,0 +1,41 @@
+package types
+
+// BuildPlan lists what a build mode would do : the builds in build order, then the local images removed once the
+// builds succeed.
+type BuildPlan struct {
+	Builds []BuildStep `json:"builds"`
+	// Remove lists the local image names untagged by `build commit --prune-removed`.
+	Remove []string `json:"remove"`
+}
+
+type BuildStep struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Dir is the dir the builder command runs in.
+	Dir       string   `json:"dir"`
+	Tags      []string `json:"tags"`
+	Platforms []string `json:"platforms"`
+	Parent    string   `json:"parent,omitempty"`
+	// Command is the builder command line, with values of secrets redacted.
+	Command []string `json:"command"`
+	// Push lists the tags pushed to registries by the build.
+	Push []string `json:"push"`
+}
+
+// LevelsPlan groups images to build by dependency level, images of a level can be built in parallel once all
+// images of previous levels are built.
+type LevelsPlan struct {
+	Levels []PlanLevel `json:"levels"`
+}
+
+type PlanLevel struct {
+	Level  int         `json:"level"`
+	Images []PlanImage `json:"images"`
+}
+
+type PlanImage struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Parents lists the local parent and dependencies which have to be built before the image.
+	Parents []string `json:"parents"`
+}
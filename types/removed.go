@@ -0,0 +1,36 @@
+package types
+
+import (
+	"slices"
+)
+
+// ImageRemoved is an image whose mib.yml was deleted or moved by a commit.
+type ImageRemoved struct {
+	Names []string
+	// Path and NewPath are the image dirs relative to the repository, NewPath is only set when the image was moved.
+	Path    string
+	NewPath string
+}
+
+func (ir ImageRemoved) IsRenamed() bool {
+	return ir.NewPath != ""
+}
+
+type ImagesRemoved []ImageRemoved
+
+// GetNamesToPrune returns the names of removed images which are not declared anymore by images.
+func (irs ImagesRemoved) GetNamesToPrune(images Images) []string {
+	declared := []string{}
+	for _, image := range images.GetAll() {
+		declared = append(declared, image.GetNames()...)
+	}
+	names := []string{}
+	for _, removed := range irs {
+		for _, name := range removed.Names {
+			if !slices.Contains(declared, name) && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
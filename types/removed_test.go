@@ -0,0 +1,50 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestImageRemoved_IsRenamed(t *testing.T) {
+	assert.False(t, ImageRemoved{Names: []string{"foo:0.1"}, Path: "foo"}.IsRenamed())
+	assert.True(t, ImageRemoved{Names: []string{"foo:0.1"}, Path: "foo", NewPath: "bar"}.IsRenamed())
+}
+
+func TestImagesRemoved_GetNamesToPrune(t *testing.T) {
+	child := &Image{ImageName: ImageName{Name: "bar", Tag: "0.1"}, Path: "/app/foo/bar", HasLocalParent: true}
+	images := Images{
+		&Image{ImageName: ImageName{Name: "foo", Tag: "0.1"}, Path: "/app/foo", Children: Images{child}},
+	}
+	tests := []struct {
+		name    string
+		removed ImagesRemoved
+		want    []string
+	}{
+		{
+			name:    "SuccessEmpty",
+			removed: ImagesRemoved{},
+			want:    []string{},
+		},
+		{
+			name: "SuccessRemoved",
+			removed: ImagesRemoved{
+				{Names: []string{"old:0.1", "old:latest"}, Path: "old"},
+			},
+			want: []string{"old:0.1", "old:latest"},
+		},
+		{
+			name: "SuccessRenamedKeepName",
+			removed: ImagesRemoved{
+				{Names: []string{"bar:0.1"}, Path: "bar", NewPath: "foo/bar"},
+				{Names: []string{"old:0.1"}, Path: "old"},
+				{Names: []string{"old:0.1"}, Path: "other/old"},
+			},
+			want: []string{"old:0.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.removed.GetNamesToPrune(images))
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package state
+
+import "time"
+
+// BuildState is the last successful build of an image.
+type BuildState struct {
+	Commit  string    `json:"commit"`
+	Digest  string    `json:"digest,omitempty"`
+	BuiltAt time.Time `json:"builtAt"`
+}
+
+type Store interface {
+	Type() string
+	// Get returns nil when the image has never been built.
+	Get(name string) (*BuildState, error)
+	Save(name string, state BuildState) error
+}